@@ -3,15 +3,19 @@ package main
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"flag"
 	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
-	"time"
 
 	"github.com/MimoJanra/DomainPulse/internal/api"
 	"github.com/MimoJanra/DomainPulse/internal/checker"
+	"github.com/MimoJanra/DomainPulse/internal/config"
+	"github.com/MimoJanra/DomainPulse/internal/events"
+	"github.com/MimoJanra/DomainPulse/internal/secrets"
 	"github.com/MimoJanra/DomainPulse/internal/storage"
 )
 
@@ -22,8 +26,27 @@ import (
 // @host      localhost:8080
 // @BasePath  /
 // @schemes   http
+
+// @securityDefinitions.apikey  ApiKeyAuth
+// @in                          header
+// @name                        X-API-Key
+// @description                 Ключ API; также принимается заголовок Authorization: Bearer <ключ>
+
+// @security  ApiKeyAuth
 func main() {
-	db, err := storage.InitDB()
+	cfg, err := config.Load(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		config.Usage(os.Stdout)
+		return
+	}
+	if err != nil {
+		log.Fatalf("invalid configuration: %v", err)
+	}
+	if info, err := os.Stat(cfg.Server.StaticDir); err != nil || !info.IsDir() {
+		log.Printf("Warning: server.static_dir %q is not a directory, web interface is not served", cfg.Server.StaticDir)
+	}
+
+	db, err := storage.InitDB(cfg.Database.Path)
 	if err != nil {
 		log.Fatalf("failed to init db: %v", err)
 	}
@@ -36,12 +59,46 @@ func main() {
 	domainRepo := storage.NewSQLiteDomainRepo(db)
 	checkRepo := storage.NewCheckRepo(db)
 	resultRepo := storage.NewResultRepo(db)
-	notificationRepo := storage.NewNotificationRepo(db)
+	keyring, err := secrets.NewKeyring(cfg.Secrets.Key, cfg.Secrets.PreviousKeys)
+	if err != nil {
+		log.Fatalf("invalid secrets key: %v", err)
+	}
+	notificationRepo := storage.NewNotificationRepo(db, keyring)
+	stateRepo := storage.NewCheckStateRepo(db)
+	incidentRepo := storage.NewIncidentRepo(db)
+	dependencyRepo := storage.NewDependencyRepo(db)
+	maintenanceRepo := storage.NewMaintenanceRepo(db)
+	userRepo := storage.NewUserRepo(db)
+	apiKeyRepo := storage.NewAPIKeyRepo(db)
+	bus := events.NewBus()
+
+	if keyring == nil {
+		log.Printf("Warning: secrets.key is not set, notification secrets are stored unencrypted")
+	}
+	reencrypted, err := notificationRepo.EncryptSecrets()
+	if err != nil {
+		log.Fatalf("failed to encrypt notification secrets: %v", err)
+	}
+	if reencrypted > 0 {
+		log.Printf("Encrypted secrets of %d notification settings with the current key", reencrypted)
+	}
 
-	checker.InitGlobalRateLimiter(1000)
+	adminKey, err := api.BootstrapAdmin(userRepo, apiKeyRepo)
+	if err != nil {
+		log.Fatalf("failed to create bootstrap admin: %v", err)
+	}
+	if adminKey != "" {
+		log.Printf("Created user 'admin' with API key %s (shown only once, store it securely)", adminKey)
+	}
+
+	checker.InitGlobalRateLimiter(cfg.Checker.GlobalRateLimit)
+	checker.SetDefaultCheckTimeout(cfg.Checker.DefaultTimeout)
 
-	workerCount := 5
-	scheduler := checker.NewScheduler(checkRepo, domainRepo, resultRepo, notificationRepo, workerCount)
+	scheduler := checker.NewScheduler(checkRepo, domainRepo, resultRepo, notificationRepo, stateRepo, incidentRepo, dependencyRepo, maintenanceRepo, bus, checker.PoolOptions{
+		Workers:             cfg.Checker.Workers,
+		QueueSize:           cfg.Checker.QueueSize,
+		NotificationTimeout: cfg.Notifications.Timeout,
+	})
 
 	scheduler.Start()
 
@@ -50,29 +107,42 @@ func main() {
 		CheckRepo:        checkRepo,
 		ResultRepo:       resultRepo,
 		NotificationRepo: notificationRepo,
+		IncidentRepo:     incidentRepo,
+		DependencyRepo:   dependencyRepo,
+		MaintenanceRepo:  maintenanceRepo,
+		UserRepo:         userRepo,
+		APIKeyRepo:       apiKeyRepo,
+		Events:           bus,
+		Scheduler:        scheduler,
+		StaticDir:        cfg.Server.StaticDir,
 	}
 
 	r := api.SetupRouter(server)
 
 	srv := &http.Server{
-		Addr:    ":8080",
+		Addr:    cfg.Server.Addr,
 		Handler: r,
 	}
 
 	sigChan := make(chan os.Signal, 1)
-	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM)
+	signal.Notify(sigChan, os.Interrupt, syscall.SIGTERM, syscall.SIGHUP)
 
 	go func() {
-		log.Println("Server started on :8080")
+		log.Printf("Server started on %s", cfg.Server.Addr)
 		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
 			log.Fatalf("HTTP server error: %v", err)
 		}
 	}()
 
-	<-sigChan
+	for sig := range sigChan {
+		if sig != syscall.SIGHUP {
+			break
+		}
+		cfg = reloadConfig(cfg, scheduler)
+	}
 	log.Println("Shutting down server...")
 
-	shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout)
 	defer cancel()
 
 	if err := srv.Shutdown(shutdownCtx); err != nil {
@@ -82,3 +152,34 @@ func main() {
 	scheduler.Stop()
 	log.Println("Server stopped")
 }
+
+// reloadConfig перечитывает конфигурацию по SIGHUP и применяет параметры, которые можно менять
+// на ходу: число воркеров (только увеличение), глобальный лимит, таймаут проверок и время остановки.
+// При ошибке сохраняется текущая конфигурация.
+func reloadConfig(cfg config.Config, scheduler *checker.Scheduler) config.Config {
+	next, err := config.Load(os.Args[1:])
+	if err != nil {
+		log.Printf("config reload failed, keeping current configuration: %v", err)
+		return cfg
+	}
+
+	next, restart := cfg.Reload(next)
+	for _, key := range restart {
+		log.Printf("config reload: %s changed, restart required to apply", key)
+	}
+
+	switch {
+	case next.Checker.Workers > cfg.Checker.Workers:
+		scheduler.SetWorkerCount(next.Checker.Workers)
+	case next.Checker.Workers < cfg.Checker.Workers:
+		log.Printf("config reload: checker.workers can only be increased without restart")
+		next.Checker.Workers = cfg.Checker.Workers
+	}
+	if checker.GlobalRateLimiter != nil {
+		checker.GlobalRateLimiter.SetLimit(next.Checker.GlobalRateLimit)
+	}
+	checker.SetDefaultCheckTimeout(next.Checker.DefaultTimeout)
+
+	log.Println("Configuration reloaded")
+	return next
+}
@@ -46,7 +46,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Создает новую проверку (http, icmp, tcp, udp) с указанием domain_id в теле запроса",
+                "description": "Создает новую проверку (http, icmp, tcp, udp, tls, dns) с указанием domain_id в теле запроса",
                 "consumes": [
                     "application/json"
                 ],
@@ -179,6 +179,98 @@ const docTemplate = `{
                 }
             }
         },
+        "/checks/{id}/dependencies": {
+            "get": {
+                "description": "Возвращает проверки, от которых зависит проверка, и проверки, которые зависят от неё",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "checks"
+                ],
+                "summary": "Получить зависимости проверки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проверки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckDependencies"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid check id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "check not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Заменяет список проверок, от которых зависит проверка. Пока любая из них недоступна,\nсбои проверки сохраняются с флагом suppressed и не вызывают уведомлений и инцидентов.\nПустой список удаляет все зависимости. Зависимости, образующие цикл, отклоняются",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "checks"
+                ],
+                "summary": "Задать зависимости проверки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проверки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "ID родительских проверок",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckDependenciesRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.CheckDependencies"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid check id, request body, unknown dependency or dependency cycle",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "check not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/checks/{id}/disable": {
             "post": {
                 "description": "Отключает проверку от выполнения",
@@ -263,6 +355,70 @@ const docTemplate = `{
                 }
             }
         },
+        "/checks/{id}/incidents": {
+            "get": {
+                "description": "Возвращает инциденты проверки (новые первыми) с пагинацией",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Получить историю инцидентов проверки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проверки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "acknowledged",
+                            "resolved"
+                        ],
+                        "type": "string",
+                        "description": "Статус инцидента",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.IncidentsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid check id or status",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "check not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/checks/{id}/intervals": {
             "get": {
                 "description": "Возвращает данные, агрегированные по тайм-интервалам (1m, 5m, 1h) для построения графиков с пагинацией",
@@ -340,6 +496,50 @@ const docTemplate = `{
                 }
             }
         },
+        "/checks/{id}/maintenance": {
+            "get": {
+                "description": "Возвращает окно обслуживания, которое действует для проверки сейчас, или 204, если такого нет",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Получить действующее окно обслуживания проверки",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проверки",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    },
+                    "204": {
+                        "description": "no active maintenance window"
+                    },
+                    "400": {
+                        "description": "invalid check id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "check not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
         "/checks/{id}/results": {
             "get": {
                 "description": "Возвращает список результатов для конкретной проверки с фильтрацией по периоду и пагинацией",
@@ -401,7 +601,7 @@ const docTemplate = `{
         },
         "/checks/{id}/stats": {
             "get": {
-                "description": "Возвращает агрегированную статистику: распределение по статусам и статистику latency",
+                "description": "Возвращает агрегированную статистику: распределение по статусам и статистику latency, для HTTP проверок — также по фазам запроса (dns, connect, tls, ttfb, transfer)",
                 "produces": [
                     "application/json"
                 ],
@@ -635,7 +835,7 @@ const docTemplate = `{
                 }
             },
             "post": {
-                "description": "Добавляет новую проверку (http, icmp, tcp, udp) для домена",
+                "description": "Добавляет новую проверку (http, icmp, tcp, udp, tls, dns) для домена",
                 "consumes": [
                     "application/json"
                 ],
@@ -680,60 +880,97 @@ const docTemplate = `{
                 }
             }
         },
-        "/notifications": {
+        "/incidents": {
             "get": {
-                "description": "Возвращает список всех настроек уведомлений",
+                "description": "Возвращает инциденты (новые первыми) с фильтрацией по проверке и статусу и пагинацией",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
+                    "incidents"
+                ],
+                "summary": "Получить список инцидентов",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID проверки",
+                        "name": "check_id",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "open",
+                            "acknowledged",
+                            "resolved"
+                        ],
+                        "type": "string",
+                        "description": "Статус инцидента",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Номер страницы",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Размер страницы",
+                        "name": "page_size",
+                        "in": "query"
+                    }
                 ],
-                "summary": "Получить настройки уведомлений",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.NotificationSettings"
-                            }
+                            "$ref": "#/definitions/models.IncidentsResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid check_id or status",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
-            },
-            "post": {
-                "description": "Создает новую настройку уведомлений для Telegram или Slack",
-                "consumes": [
-                    "application/json"
-                ],
+            }
+        },
+        "/incidents/{id}": {
+            "get": {
+                "description": "Возвращает инцидент вместе с результатами проверок, которые к нему привели",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
+                    "incidents"
                 ],
-                "summary": "Создать настройки уведомлений",
+                "summary": "Получить инцидент",
                 "parameters": [
                     {
-                        "description": "Настройки уведомлений",
-                        "name": "settings",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "type": "object"
-                        }
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.NotificationSettings"
+                            "$ref": "#/definitions/models.Incident"
                         }
                     },
                     "400": {
-                        "description": "invalid request body",
+                        "description": "invalid incident id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "incident not found",
                         "schema": {
                             "type": "string"
                         }
@@ -741,9 +978,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/notifications/{id}": {
-            "put": {
-                "description": "Обновляет настройки уведомлений по ID",
+        "/incidents/{id}/acknowledge": {
+            "post": {
+                "description": "Отмечает инцидент как взятый в работу: кто и с каким комментарием.\nЕсли acknowledged_by не указан, подставляется имя пользователя ключа API",
                 "consumes": [
                     "application/json"
                 ],
@@ -751,24 +988,24 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
+                    "incidents"
                 ],
-                "summary": "Обновить настройки уведомлений",
+                "summary": "Подтвердить инцидент",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID настроек",
+                        "description": "ID инцидента",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "Настройки уведомлений",
-                        "name": "settings",
+                        "description": "Кто подтверждает и комментарий",
+                        "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "type": "object"
+                            "$ref": "#/definitions/models.IncidentAckRequest"
                         }
                     }
                 ],
@@ -776,36 +1013,102 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/models.NotificationSettings"
+                            "$ref": "#/definitions/models.Incident"
                         }
                     },
                     "400": {
-                        "description": "invalid request body",
+                        "description": "invalid incident id or request body",
                         "schema": {
                             "type": "string"
                         }
                     },
                     "404": {
-                        "description": "notification settings not found",
+                        "description": "incident not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "incident already resolved",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/incidents/{id}/resolve": {
+            "post": {
+                "description": "Закрывает инцидент вручную. Инциденты закрываются автоматически, когда проверка восстанавливается",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "incidents"
+                ],
+                "summary": "Закрыть инцидент",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID инцидента",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Комментарий",
+                        "name": "request",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.IncidentResolveRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.Incident"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid incident id or request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "incident not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "incident already resolved",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/keys/{id}": {
             "delete": {
-                "description": "Удаляет настройки уведомлений по ID",
+                "description": "Удаляет ключ API (только admin); запросы с ним сразу перестают проходить",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
+                    "users"
                 ],
-                "summary": "Удалить настройки уведомлений",
+                "summary": "Отозвать ключ API",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID настроек",
+                        "description": "ID ключа",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -821,8 +1124,14 @@ const docTemplate = `{
                             }
                         }
                     },
+                    "400": {
+                        "description": "invalid api key id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
                     "404": {
-                        "description": "notification settings not found",
+                        "description": "api key not found",
                         "schema": {
                             "type": "string"
                         }
@@ -830,20 +1139,81 @@ const docTemplate = `{
                 }
             }
         },
-        "/notifications/{id}/disable": {
+        "/maintenance": {
+            "get": {
+                "description": "Возвращает все окна обслуживания",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Получить окна обслуживания",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.MaintenanceWindow"
+                            }
+                        }
+                    }
+                }
+            },
             "post": {
-                "description": "Отключает настройки уведомлений",
+                "description": "Создаёт разовое (starts_at, ends_at) или повторяющееся (cron, duration_minutes) окно обслуживания\nдля проверки, домена или всех проверок. Новое окно включено, если не указано enabled: false",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
+                    "maintenance"
                 ],
-                "summary": "Отключить уведомления",
+                "summary": "Создать окно обслуживания",
+                "parameters": [
+                    {
+                        "description": "Окно обслуживания",
+                        "name": "window",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body or window parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/maintenance/{id}": {
+            "get": {
+                "description": "Возвращает окно обслуживания по ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Получить окно обслуживания",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID настроек",
+                        "description": "ID окна",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -853,33 +1223,87 @@ const docTemplate = `{
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid maintenance window id",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "404": {
-                        "description": "notification settings not found",
+                        "description": "maintenance window not found",
                         "schema": {
                             "type": "string"
                         }
                     }
                 }
-            }
-        },
-        "/notifications/{id}/enable": {
-            "post": {
-                "description": "Включает настройки уведомлений",
+            },
+            "put": {
+                "description": "Заменяет параметры окна обслуживания",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "notifications"
+                    "maintenance"
                 ],
-                "summary": "Включить уведомления",
+                "summary": "Обновить окно обслуживания",
                 "parameters": [
                     {
                         "type": "integer",
-                        "description": "ID настроек",
+                        "description": "ID окна",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Окно обслуживания",
+                        "name": "window",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.MaintenanceWindow"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body or window parameters",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "maintenance window not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет окно обслуживания по ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "maintenance"
+                ],
+                "summary": "Удалить окно обслуживания",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID окна",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -890,11 +1314,19 @@ const docTemplate = `{
                         "description": "OK",
                         "schema": {
                             "type": "object",
-                            "additionalProperties": true
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid maintenance window id",
+                        "schema": {
+                            "type": "string"
                         }
                     },
                     "404": {
-                        "description": "notification settings not found",
+                        "description": "maintenance window not found",
                         "schema": {
                             "type": "string"
                         }
@@ -902,145 +1334,1057 @@ const docTemplate = `{
                 }
             }
         },
-        "/results": {
+        "/me": {
             "get": {
-                "description": "Возвращает список всех результатов проверок",
+                "description": "Возвращает пользователя, которому принадлежит ключ API запроса",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "results"
+                    "users"
                 ],
-                "summary": "Получить результаты проверок",
+                "summary": "Текущий пользователь",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "array",
-                            "items": {
-                                "$ref": "#/definitions/models.Result"
-                            }
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "401": {
+                        "description": "api key required",
+                        "schema": {
+                            "type": "string"
                         }
                     }
                 }
             }
         },
-        "/run-check": {
-            "post": {
-                "description": "Выполняет все проверки и записывает результаты в БД",
+        "/metrics": {
+            "get": {
+                "description": "Возвращает метрики в текстовом формате Prometheus: доступность, длительность последней проверки\nи число результатов по статусу и исходу для каждой проверки (метки check_id, domain, type),\nа также состояние очереди задач, серии ошибок проверок и отправку уведомлений",
+                "produces": [
+                    "text/plain"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Метрики Prometheus",
+                "responses": {
+                    "200": {
+                        "description": "метрики в текстовом формате Prometheus",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "503": {
+                        "description": "scheduler is not running",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications": {
+            "get": {
+                "description": "Возвращает список всех настроек уведомлений. Секретные поля (token, webhook_url, secret, smtp_password, значения headers) замаскированы",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "checks"
+                    "notifications"
                 ],
-                "summary": "Запустить все проверки вручную",
+                "summary": "Получить настройки уведомлений",
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "type": "object",
-                            "additionalProperties": true
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.NotificationSettings"
+                            }
                         }
                     }
                 }
-            }
-        }
-    },
-    "definitions": {
-        "models.Check": {
-            "type": "object",
-            "properties": {
-                "domain_id": {
+            },
+            "post": {
+                "description": "Создает новую настройку уведомлений для Telegram, Slack, вебхука, email (SMTP), Discord, Microsoft Teams, Mattermost, PagerDuty или Opsgenie.\nВебхук получает POST с JSON-событием (версия в поле version) и, если задан secret, подписью HMAC-SHA256 в заголовке X-DomainPulse-Signature",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Создать настройки уведомлений",
+                "parameters": [
+                    {
+                        "description": "Настройки уведомлений",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}": {
+            "put": {
+                "description": "Обновляет настройки уведомлений по ID. Замаскированное значение секретного поля,\nотправленное без изменений, сохраняет прежний секрет",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Обновить настройки уведомлений",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID настроек",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Настройки уведомлений",
+                        "name": "settings",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "type": "object"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.NotificationSettings"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "notification settings not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет настройки уведомлений по ID",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Удалить настройки уведомлений",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID настроек",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "404": {
+                        "description": "notification settings not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/disable": {
+            "post": {
+                "description": "Отключает настройки уведомлений",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Отключить уведомления",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID настроек",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "notification settings not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/notifications/{id}/enable": {
+            "post": {
+                "description": "Включает настройки уведомлений",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "notifications"
+                ],
+                "summary": "Включить уведомления",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID настроек",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    },
+                    "404": {
+                        "description": "notification settings not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/queue": {
+            "get": {
+                "description": "Возвращает глубину очереди задач, число объединённых дубликатов и запусков,\nотброшенных из-за переполнения (всего и по проверкам). Отброшенные запуски сохраняются в истории со статусом skipped",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "system"
+                ],
+                "summary": "Получить состояние очереди проверок",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.QueueStats"
+                        }
+                    },
+                    "503": {
+                        "description": "scheduler is not running",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/results": {
+            "get": {
+                "description": "Возвращает список всех результатов проверок",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "results"
+                ],
+                "summary": "Получить результаты проверок",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.Result"
+                            }
+                        }
+                    }
+                }
+            }
+        },
+        "/run-check": {
+            "post": {
+                "description": "Выполняет все проверки и записывает результаты в БД",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "checks"
+                ],
+                "summary": "Запустить все проверки вручную",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": true
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Возвращает всех пользователей API (только admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Получить пользователей",
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.User"
+                            }
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создаёт пользователя с ролью viewer, editor или admin (только admin). Ключи создаются отдельно",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Создать пользователя",
+                "parameters": [
+                    {
+                        "description": "Пользователь",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body, name or role",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "user already exists",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "put": {
+                "description": "Меняет имя и роль пользователя (только admin). Роль единственного администратора понизить нельзя",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Обновить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Пользователь",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/models.UserRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/models.User"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid request body, name or role",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "user already exists or cannot remove the last admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Удаляет пользователя и все его ключи (только admin). Единственного администратора удалить нельзя",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Удалить пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "object",
+                            "additionalProperties": {
+                                "type": "integer"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid user id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "409": {
+                        "description": "cannot remove the last admin",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/keys": {
+            "get": {
+                "description": "Возвращает ключи API пользователя без самих ключей (только admin)",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Получить ключи пользователя",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/models.APIKey"
+                            }
+                        }
+                    },
+                    "400": {
+                        "description": "invalid user id",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Создаёт ключ API пользователя (только admin). Ключ возвращается только в этом ответе,\nв БД хранится его хеш. Ключ передаётся в заголовке X-API-Key или Authorization: Bearer",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Создать ключ API",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "ID пользователя",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Ключ",
+                        "name": "key",
+                        "in": "body",
+                        "schema": {
+                            "$ref": "#/definitions/models.APIKeyRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/models.CreatedAPIKey"
+                        }
+                    },
+                    "400": {
+                        "description": "invalid user id or request body",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "404": {
+                        "description": "user not found",
+                        "schema": {
+                            "type": "string"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "models.APIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-01-02T08:30:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "grafana"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "dp_Xk3vQ9aB"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.APIKeyRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "grafana"
+                }
+            }
+        },
+        "models.Check": {
+            "type": "object",
+            "properties": {
+                "domain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "interval_seconds": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "params": {
+                    "$ref": "#/definitions/models.CheckParams"
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/"
+                },
+                "rate_limit_per_minute": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "realtime_mode": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "type": {
+                    "type": "string",
+                    "example": "http"
+                }
+            }
+        },
+        "models.CheckDependencies": {
+            "type": "object",
+            "properties": {
+                "check_id": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "dependents": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                },
+                "depends_on": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.CheckDependenciesRequest": {
+            "type": "object",
+            "properties": {
+                "depends_on": {
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    }
+                }
+            }
+        },
+        "models.CheckParams": {
+            "type": "object",
+            "properties": {
+                "body": {
+                    "type": "string",
+                    "example": ""
+                },
+                "body_contains": {
+                    "type": "string",
+                    "example": "healthy"
+                },
+                "body_not_contains": {
+                    "type": "string",
+                    "example": "Internal Server Error"
+                },
+                "body_regex": {
+                    "type": "string",
+                    "example": "^OK$"
+                },
+                "ca_bundle": {
+                    "type": "string",
+                    "example": "-----BEGIN CERTIFICATE-----..."
+                },
+                "cert_expiry_fail_days": {
+                    "type": "integer",
+                    "example": 7
+                },
+                "cert_expiry_warn_days": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "*/5 9-18 * * mon-fri"
+                },
+                "cron_timezone": {
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                },
+                "expect": {
+                    "type": "string",
+                    "example": "+PONG"
+                },
+                "expect_mode": {
+                    "type": "string",
+                    "example": "string"
+                },
+                "expected_status": {
+                    "type": "string",
+                    "example": "200,204,300-399"
+                },
+                "expected_values": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "93.184.216.34"
+                    ]
+                },
+                "fail_on_no_response": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "follow_redirects": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    },
+                    "example": {
+                        " \"X-Custom-Header\"": " \"value\"}",
+                        "{\"Authorization\"": " \"Bearer token\""
+                    }
+                },
+                "json_path": {
+                    "type": "string",
+                    "example": "$.checks.db.status"
+                },
+                "json_path_equals": {
+                    "type": "string",
+                    "example": "up"
+                },
+                "max_body_bytes": {
+                    "type": "integer",
+                    "example": 1048576
+                },
+                "max_packet_loss_percent": {
+                    "type": "number",
+                    "example": 20
+                },
+                "max_redirects": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "max_response_bytes": {
+                    "type": "integer",
+                    "example": 4096
+                },
+                "method": {
+                    "type": "string",
+                    "example": "GET"
+                },
+                "min_records": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "packet_count": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "packet_interval_ms": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "path": {
+                    "type": "string",
+                    "example": "/health"
+                },
+                "payload": {
+                    "type": "string",
+                    "example": "ping"
+                },
+                "payload_encoding": {
+                    "type": "string",
+                    "example": "hex"
+                },
+                "port": {
+                    "type": "integer",
+                    "example": 80
+                },
+                "query_name": {
+                    "type": "string",
+                    "example": "_sip._tcp.example.com"
+                },
+                "read_timeout_ms": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "record_type": {
+                    "type": "string",
+                    "example": "A"
+                },
+                "renotify_interval_minutes": {
+                    "type": "integer",
+                    "example": 30
+                },
+                "resolver": {
+                    "type": "string",
+                    "example": "8.8.8.8:53"
+                },
+                "retries": {
+                    "type": "integer",
+                    "example": 2
+                },
+                "retry_delay_ms": {
+                    "type": "integer",
+                    "example": 2000
+                },
+                "scheme": {
+                    "type": "string",
+                    "example": "https"
+                },
+                "severity": {
+                    "type": "string",
+                    "example": "critical"
+                },
+                "timeout_ms": {
+                    "type": "integer",
+                    "example": 5000
+                },
+                "verify_certificate": {
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "models.CreatedAPIKey": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "key": {
+                    "type": "string",
+                    "example": "dp_Xk3vQ9aB..."
+                },
+                "last_used_at": {
+                    "type": "string",
+                    "example": "2024-01-02T08:30:00Z"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "grafana"
+                },
+                "prefix": {
+                    "type": "string",
+                    "example": "dp_Xk3vQ9aB"
+                },
+                "user_id": {
+                    "type": "integer",
+                    "example": 1
+                }
+            }
+        },
+        "models.Domain": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "example.com"
+                }
+            }
+        },
+        "models.Incident": {
+            "type": "object",
+            "properties": {
+                "ack_note": {
+                    "type": "string",
+                    "example": "investigating database"
+                },
+                "acknowledged_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:05:00Z"
+                },
+                "acknowledged_by": {
+                    "type": "string",
+                    "example": "oncall"
+                },
+                "cause": {
+                    "type": "string",
+                    "example": "connection refused"
+                },
+                "check_id": {
                     "type": "integer",
                     "example": 1
                 },
-                "enabled": {
-                    "type": "boolean",
-                    "example": true
+                "duration_seconds": {
+                    "type": "integer",
+                    "example": 1800
                 },
                 "id": {
                     "type": "integer",
                     "example": 1
                 },
-                "interval_seconds": {
-                    "type": "integer",
-                    "example": 60
-                },
-                "params": {
-                    "$ref": "#/definitions/models.CheckParams"
+                "resolution_note": {
+                    "type": "string",
+                    "example": "auto-resolved: check recovered"
                 },
-                "path": {
+                "resolved_at": {
                     "type": "string",
-                    "example": "/"
+                    "example": "2024-01-01T12:30:00Z"
                 },
-                "rate_limit_per_minute": {
+                "result_count": {
                     "type": "integer",
-                    "example": 60
+                    "example": 180
                 },
-                "realtime_mode": {
-                    "type": "boolean",
-                    "example": false
+                "results": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Result"
+                    }
                 },
-                "type": {
+                "started_at": {
                     "type": "string",
-                    "example": "http"
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "status": {
+                    "type": "string",
+                    "example": "acknowledged"
                 }
             }
         },
-        "models.CheckParams": {
+        "models.IncidentAckRequest": {
             "type": "object",
             "properties": {
-                "body": {
+                "acknowledged_by": {
                     "type": "string",
-                    "example": ""
-                },
-                "headers": {
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "string"
-                    },
-                    "example": {
-                        " \"X-Custom-Header\"": " \"value\"}",
-                        "{\"Authorization\"": " \"Bearer token\""
-                    }
-                },
-                "method": {
-                    "type": "string",
-                    "example": "GET"
-                },
-                "path": {
-                    "type": "string",
-                    "example": "/health"
+                    "example": "oncall"
                 },
-                "payload": {
+                "note": {
                     "type": "string",
-                    "example": "ping"
-                },
-                "port": {
-                    "type": "integer",
-                    "example": 80
-                },
-                "scheme": {
+                    "example": "investigating database"
+                }
+            }
+        },
+        "models.IncidentResolveRequest": {
+            "type": "object",
+            "properties": {
+                "note": {
                     "type": "string",
-                    "example": "https"
-                },
-                "timeout_ms": {
-                    "type": "integer",
-                    "example": 5000
+                    "example": "fixed by restarting the service"
                 }
             }
         },
-        "models.Domain": {
+        "models.IncidentsResponse": {
             "type": "object",
             "properties": {
-                "id": {
-                    "type": "integer",
-                    "example": 1
+                "incidents": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/models.Incident"
+                    }
                 },
-                "name": {
-                    "type": "string",
-                    "example": "example.com"
+                "page": {
+                    "type": "integer"
+                },
+                "page_size": {
+                    "type": "integer"
+                },
+                "total": {
+                    "type": "integer"
+                },
+                "total_pages": {
+                    "type": "integer"
                 }
             }
         },
@@ -1073,17 +2417,101 @@ const docTemplate = `{
                 }
             }
         },
+        "models.MaintenanceWindow": {
+            "type": "object",
+            "properties": {
+                "check_id": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "cron": {
+                    "type": "string",
+                    "example": "0 3 * * sun"
+                },
+                "domain_id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "duration_minutes": {
+                    "type": "integer",
+                    "example": 60
+                },
+                "enabled": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "ends_at": {
+                    "type": "string",
+                    "example": "2024-01-01T04:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "mode": {
+                    "type": "string",
+                    "example": "skip"
+                },
+                "name": {
+                    "type": "string",
+                    "example": "Обновление роутера"
+                },
+                "scope": {
+                    "type": "string",
+                    "example": "domain"
+                },
+                "starts_at": {
+                    "type": "string",
+                    "example": "2024-01-01T02:00:00Z"
+                },
+                "timezone": {
+                    "type": "string",
+                    "example": "Europe/Moscow"
+                }
+            }
+        },
         "models.NotificationSettings": {
             "type": "object",
             "properties": {
+                "api_url": {
+                    "type": "string",
+                    "example": "https://api.eu.opsgenie.com"
+                },
+                "channel": {
+                    "type": "string",
+                    "example": "town-square"
+                },
                 "chat_id": {
                     "type": "string",
                     "example": "-1001234567890"
                 },
+                "email_from": {
+                    "type": "string",
+                    "example": "DomainPulse \u003calerts@example.com\u003e"
+                },
+                "email_to": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "ops@example.com"
+                    ]
+                },
                 "enabled": {
                     "type": "boolean",
                     "example": true
                 },
+                "headers": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "id": {
                     "type": "integer",
                     "example": 1
@@ -1100,10 +2528,34 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": false
                 },
+                "secret": {
+                    "type": "string",
+                    "example": "whsec_3f9a..."
+                },
                 "slow_response_threshold_ms": {
                     "type": "integer",
                     "example": 1000
                 },
+                "smtp_host": {
+                    "type": "string",
+                    "example": "smtp.example.com"
+                },
+                "smtp_password": {
+                    "type": "string",
+                    "example": "app-password"
+                },
+                "smtp_port": {
+                    "type": "integer",
+                    "example": 587
+                },
+                "smtp_security": {
+                    "type": "string",
+                    "example": "starttls"
+                },
+                "smtp_username": {
+                    "type": "string",
+                    "example": "alerts@example.com"
+                },
                 "token": {
                     "type": "string",
                     "example": "123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11"
@@ -1112,15 +2564,109 @@ const docTemplate = `{
                     "type": "string",
                     "example": "telegram"
                 },
+                "username": {
+                    "type": "string",
+                    "example": "DomainPulse"
+                },
                 "webhook_url": {
                     "type": "string",
                     "example": "https://hooks.slack.com/services/..."
                 }
             }
         },
+        "models.PhaseAverages": {
+            "type": "object",
+            "properties": {
+                "connect_ms": {
+                    "type": "number",
+                    "example": 25.1
+                },
+                "dns_ms": {
+                    "type": "number",
+                    "example": 12.5
+                },
+                "tls_ms": {
+                    "type": "number",
+                    "example": 40.2
+                },
+                "transfer_ms": {
+                    "type": "number",
+                    "example": 5.3
+                },
+                "ttfb_ms": {
+                    "type": "number",
+                    "example": 60.7
+                }
+            }
+        },
+        "models.PhaseTimings": {
+            "type": "object",
+            "properties": {
+                "connect_ms": {
+                    "type": "integer",
+                    "example": 25
+                },
+                "dns_ms": {
+                    "type": "integer",
+                    "example": 12
+                },
+                "tls_ms": {
+                    "type": "integer",
+                    "example": 40
+                },
+                "transfer_ms": {
+                    "type": "integer",
+                    "example": 5
+                },
+                "ttfb_ms": {
+                    "type": "integer",
+                    "example": 60
+                }
+            }
+        },
+        "models.QueueStats": {
+            "type": "object",
+            "properties": {
+                "capacity": {
+                    "type": "integer",
+                    "example": 100
+                },
+                "coalesced": {
+                    "type": "integer",
+                    "example": 4
+                },
+                "depth": {
+                    "type": "integer",
+                    "example": 3
+                },
+                "dropped": {
+                    "type": "integer",
+                    "example": 0
+                },
+                "dropped_by_check": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer",
+                        "format": "int64"
+                    }
+                },
+                "enqueued": {
+                    "type": "integer",
+                    "example": 1200
+                },
+                "workers": {
+                    "type": "integer",
+                    "example": 5
+                }
+            }
+        },
         "models.Result": {
             "type": "object",
             "properties": {
+                "attempts": {
+                    "type": "integer",
+                    "example": 1
+                },
                 "check_id": {
                     "type": "integer",
                     "example": 1
@@ -1129,6 +2675,10 @@ const docTemplate = `{
                     "type": "string",
                     "example": "2024-01-01T12:00:00Z"
                 },
+                "details": {
+                    "type": "object",
+                    "additionalProperties": {}
+                },
                 "duration_ms": {
                     "type": "integer",
                     "example": 150
@@ -1152,6 +2702,13 @@ const docTemplate = `{
                 "status_code": {
                     "type": "integer",
                     "example": 200
+                },
+                "suppressed": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "timings": {
+                    "$ref": "#/definitions/models.PhaseTimings"
                 }
             }
         },
@@ -1184,6 +2741,12 @@ const docTemplate = `{
                 "latency_stats": {
                     "$ref": "#/definitions/models.LatencyStats"
                 },
+                "phase_stats": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/models.LatencyStats"
+                    }
+                },
                 "status_distribution": {
                     "type": "object",
                     "additionalProperties": {
@@ -1203,6 +2766,9 @@ const docTemplate = `{
                     "type": "number",
                     "example": 150.5
                 },
+                "avg_phases": {
+                    "$ref": "#/definitions/models.PhaseAverages"
+                },
                 "count": {
                     "type": "integer",
                     "example": 60
@@ -1261,8 +2827,65 @@ const docTemplate = `{
                     "type": "integer"
                 }
             }
+        },
+        "models.User": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                },
+                "id": {
+                    "type": "integer",
+                    "example": 1
+                },
+                "name": {
+                    "type": "string",
+                    "example": "oncall"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "viewer",
+                        "editor",
+                        "admin"
+                    ],
+                    "example": "editor"
+                }
+            }
+        },
+        "models.UserRequest": {
+            "type": "object",
+            "properties": {
+                "name": {
+                    "type": "string",
+                    "example": "oncall"
+                },
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "viewer",
+                        "editor",
+                        "admin"
+                    ],
+                    "example": "editor"
+                }
+            }
+        }
+    },
+    "securityDefinitions": {
+        "ApiKeyAuth": {
+            "description": "Ключ API; также принимается заголовок Authorization: Bearer \u003cключ\u003e",
+            "type": "apiKey",
+            "name": "X-API-Key",
+            "in": "header"
+        }
+    },
+    "security": [
+        {
+            "ApiKeyAuth": []
         }
-    }
+    ]
 }`
 
 // SwaggerInfo holds exported Swagger Info so clients can modify it
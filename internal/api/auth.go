@@ -0,0 +1,75 @@
+package api
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"net/http"
+	"strings"
+
+	"github.com/MimoJanra/DomainPulse/internal/auth"
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// apiKeyFromRequest извлекает ключ из заголовка X-API-Key или Authorization: Bearer.
+func apiKeyFromRequest(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	scheme, token, ok := strings.Cut(r.Header.Get("Authorization"), " ")
+	if ok && strings.EqualFold(scheme, "Bearer") {
+		return strings.TrimSpace(token)
+	}
+	return ""
+}
+
+// authenticate пропускает только запросы с действующим ключом API и сохраняет владельца ключа в контексте.
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := apiKeyFromRequest(r)
+		if key == "" {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="DomainPulse"`)
+			writeError(w, http.StatusUnauthorized, "api key required")
+			return
+		}
+
+		user, err := s.APIKeyRepo.Authenticate(auth.HashKey(key))
+		if errors.Is(err, sql.ErrNoRows) {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="DomainPulse", error="invalid_token"`)
+			writeError(w, http.StatusUnauthorized, "invalid api key")
+			return
+		}
+		if err != nil {
+			log.Printf("failed to authenticate api key: %v", err)
+			writeError(w, http.StatusInternalServerError, "failed to authenticate")
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), userContextKey, user)))
+	})
+}
+
+// requireRole пропускает запросы пользователей с ролью не ниже role. Используется после authenticate.
+func requireRole(role string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			user, ok := currentUser(r)
+			if !ok || !auth.Allows(user.Role, role) {
+				writeError(w, http.StatusForbidden, "role '"+role+"' required")
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// currentUser возвращает пользователя, выполнившего запрос.
+func currentUser(r *http.Request) (models.User, bool) {
+	user, ok := r.Context().Value(userContextKey).(models.User)
+	return user, ok
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+	"github.com/MimoJanra/DomainPulse/internal/storage"
+)
+
+// --- Check dependency handlers ---
+
+// GetCheckDependencies godoc
+// @Summary Получить зависимости проверки
+// @Description Возвращает проверки, от которых зависит проверка, и проверки, которые зависят от неё
+// @Tags checks
+// @Produce json
+// @Param id path int true "ID проверки"
+// @Success 200 {object} models.CheckDependencies
+// @Failure 400 {string} string "invalid check id"
+// @Failure 404 {string} string "check not found"
+// @Router /checks/{id}/dependencies [get]
+func (s *Server) GetCheckDependencies(w http.ResponseWriter, r *http.Request) {
+	checkID, err := parseCheckID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.CheckRepo.GetByID(checkID); err != nil {
+		writeError(w, http.StatusNotFound, "check not found")
+		return
+	}
+
+	s.writeCheckDependencies(w, checkID)
+}
+
+// SetCheckDependencies godoc
+// @Summary Задать зависимости проверки
+// @Description Заменяет список проверок, от которых зависит проверка. Пока любая из них недоступна,
+// @Description сбои проверки сохраняются с флагом suppressed и не вызывают уведомлений и инцидентов.
+// @Description Пустой список удаляет все зависимости. Зависимости, образующие цикл, отклоняются
+// @Tags checks
+// @Accept json
+// @Produce json
+// @Param id path int true "ID проверки"
+// @Param request body models.CheckDependenciesRequest true "ID родительских проверок"
+// @Success 200 {object} models.CheckDependencies
+// @Failure 400 {string} string "invalid check id, request body, unknown dependency or dependency cycle"
+// @Failure 404 {string} string "check not found"
+// @Router /checks/{id}/dependencies [put]
+func (s *Server) SetCheckDependencies(w http.ResponseWriter, r *http.Request) {
+	checkID, err := parseCheckID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.CheckRepo.GetByID(checkID); err != nil {
+		writeError(w, http.StatusNotFound, "check not found")
+		return
+	}
+
+	var body models.CheckDependenciesRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	for _, parentID := range body.DependsOn {
+		if parentID == checkID {
+			writeError(w, http.StatusBadRequest, "check cannot depend on itself")
+			return
+		}
+		if _, err := s.CheckRepo.GetByID(parentID); err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Sprintf("dependency check %d not found", parentID))
+			return
+		}
+	}
+
+	err = s.DependencyRepo.SetParents(checkID, body.DependsOn)
+	if errors.Is(err, storage.ErrDependencyCycle) {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update dependencies")
+		return
+	}
+
+	s.writeCheckDependencies(w, checkID)
+}
+
+func (s *Server) writeCheckDependencies(w http.ResponseWriter, checkID int) {
+	parents, err := s.DependencyRepo.GetParents(checkID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get dependencies")
+		return
+	}
+	children, err := s.DependencyRepo.GetChildren(checkID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get dependencies")
+		return
+	}
+	writeJSON(w, http.StatusOK, models.CheckDependencies{
+		CheckID:    checkID,
+		DependsOn:  parents,
+		Dependents: children,
+	})
+}
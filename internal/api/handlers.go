@@ -13,7 +13,10 @@ import (
 	"time"
 
 	"github.com/MimoJanra/DomainPulse/internal/checker"
+	"github.com/MimoJanra/DomainPulse/internal/events"
 	"github.com/MimoJanra/DomainPulse/internal/models"
+	"github.com/MimoJanra/DomainPulse/internal/notifications"
+	"github.com/MimoJanra/DomainPulse/internal/secrets"
 	"github.com/MimoJanra/DomainPulse/internal/storage"
 
 	"github.com/go-chi/chi/v5"
@@ -24,6 +27,15 @@ type Server struct {
 	CheckRepo        *storage.CheckRepo
 	ResultRepo       *storage.ResultRepo
 	NotificationRepo *storage.NotificationRepo
+	IncidentRepo     *storage.IncidentRepo
+	DependencyRepo   *storage.DependencyRepo
+	MaintenanceRepo  *storage.MaintenanceRepo
+	UserRepo         *storage.UserRepo
+	APIKeyRepo       *storage.APIKeyRepo
+	Events           *events.Bus
+	Scheduler        *checker.Scheduler
+	// StaticDir — каталог веб-интерфейса с index.html и static/; по умолчанию web.
+	StaticDir string
 }
 
 func writeJSON(w http.ResponseWriter, status int, data any) {
@@ -38,16 +50,13 @@ func writeError(w http.ResponseWriter, status int, msg string) {
 	http.Error(w, msg, status)
 }
 
-var domainRegex = regexp.MustCompile(`^(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,}$`)
-
-var supportedCheckTypes = map[string]struct{}{
-	"http": {},
-	"icmp": {},
-	"tcp":  {},
-	"udp":  {},
-	"tls":  {},
+// publishCheckEvent сообщает планировщику, что проверку нужно перепланировать.
+func (s *Server) publishCheckEvent(eventType events.CheckEventType, checkID int) {
+	s.Events.Publish(events.CheckEvent{Type: eventType, CheckID: checkID})
 }
 
+var domainRegex = regexp.MustCompile(`^(?:[a-z0-9](?:[a-z0-9-]{0,61}[a-z0-9])?\.)+[a-z]{2,}$`)
+
 // --- Helper functions ---
 
 func parseCheckID(r *http.Request) (int, error) {
@@ -97,44 +106,92 @@ func parsePagination(r *http.Request, defaultPageSize int) (page, pageSize int)
 	return page, pageSize
 }
 
-func validateCheckParams(checkType string, params *models.CheckParams) error {
-	switch checkType {
-	case "http":
-		if params.Path == "" {
-			params.Path = "/"
-		}
-	case "tcp", "udp":
-		if params.Port <= 0 {
-			return errors.New("port is required for tcp/udp checks")
-		}
-	case "tls":
-		if params.Port <= 0 {
-			return errors.New("port is required for tls check")
-		}
-	}
-	return nil
-}
-
 func validateNotificationSettings(settings models.NotificationSettings) error {
-	if settings.Type != "telegram" && settings.Type != "slack" {
-		return errors.New("type must be 'telegram' or 'slack'")
+	if !notifications.SupportedType(settings.Type) {
+		return errors.New("type must be 'telegram', 'slack', 'webhook', 'email', 'discord', 'teams', 'mattermost', 'pagerduty' or 'opsgenie'")
 	}
 
-	if settings.Type == "telegram" {
+	if settings.Type == notifications.TypeTelegram {
 		if settings.Token == "" || settings.ChatID == "" {
 			return errors.New("token and chat_id are required for telegram")
 		}
 	}
 
-	if settings.Type == "slack" {
+	if settings.Type == notifications.TypeSlack {
 		if settings.WebhookURL == "" {
 			return errors.New("webhook_url is required for slack")
 		}
 	}
 
+	if settings.Type == notifications.TypeWebhook {
+		if settings.WebhookURL == "" {
+			return errors.New("webhook_url is required for webhook")
+		}
+		if err := notifications.ValidateWebhook(settings.WebhookURL, settings.Headers); err != nil {
+			return err
+		}
+	} else if settings.Secret != "" || len(settings.Headers) > 0 {
+		return errors.New("secret and headers are supported only for webhook")
+	}
+
+	if settings.Type == notifications.TypeEmail {
+		if err := notifications.ValidateEmail(settings); err != nil {
+			return err
+		}
+	}
+
+	switch settings.Type {
+	case notifications.TypeDiscord, notifications.TypeTeams, notifications.TypeMattermost:
+		if settings.WebhookURL == "" {
+			return errors.New("webhook_url is required for " + settings.Type)
+		}
+		if err := notifications.ValidateChat(settings); err != nil {
+			return err
+		}
+	default:
+		if settings.Username != "" || settings.Channel != "" {
+			return errors.New("username and channel are supported only for discord and mattermost")
+		}
+	}
+
+	if settings.Type == notifications.TypePagerDuty || settings.Type == notifications.TypeOpsgenie {
+		if err := notifications.ValidatePaging(settings); err != nil {
+			return err
+		}
+	} else if settings.APIURL != "" {
+		return errors.New("api_url is supported only for pagerduty and opsgenie")
+	}
+
 	return nil
 }
 
+// maskNotificationSecrets скрывает секретные поля и значения заголовков вебхука перед отправкой клиенту.
+func maskNotificationSecrets(settings models.NotificationSettings) models.NotificationSettings {
+	for _, field := range storage.NotificationSecrets(&settings) {
+		*field = secrets.Mask(*field)
+	}
+	if len(settings.Headers) > 0 {
+		masked := make(map[string]string, len(settings.Headers))
+		for name, value := range settings.Headers {
+			masked[name] = secrets.Mask(value)
+		}
+		settings.Headers = masked
+	}
+	return settings
+}
+
+// unmaskNotificationSecrets возвращает сохранённые значения секретных полей, которые клиент
+// прислал обратно в замаскированном виде, чтобы обновление без изменения секрета его не затирало.
+func unmaskNotificationSecrets(settings *models.NotificationSettings, stored models.NotificationSettings) {
+	fields, storedFields := storage.NotificationSecrets(settings), storage.NotificationSecrets(&stored)
+	for i, field := range fields {
+		*field = secrets.Unmask(*field, *storedFields[i])
+	}
+	for name, value := range settings.Headers {
+		settings.Headers[name] = secrets.Unmask(value, stored.Headers[name])
+	}
+}
+
 func validateDomain(raw string) (string, error) {
 	raw = strings.TrimSpace(raw)
 	if raw == "" {
@@ -275,7 +332,7 @@ func (s *Server) GetCheck(w http.ResponseWriter, r *http.Request) {
 
 // CreateCheck godoc
 // @Summary Добавить проверку для домена
-// @Description Добавляет новую проверку (http, icmp, tcp, udp) для домена
+// @Description Добавляет новую проверку (http, icmp, tcp, udp, tls, dns) для домена
 // @Tags checks
 // @Accept json
 // @Produce json
@@ -305,7 +362,7 @@ func (s *Server) CreateCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, ok := supportedCheckTypes[strings.ToLower(body.Type)]; !ok {
+	if _, ok := checker.Lookup(body.Type); !ok {
 		writeError(w, http.StatusBadRequest, "unsupported check type")
 		return
 	}
@@ -319,7 +376,7 @@ func (s *Server) CreateCheck(w http.ResponseWriter, r *http.Request) {
 	}
 
 	body.Type = strings.ToLower(body.Type)
-	if err := validateCheckParams(body.Type, &body.Params); err != nil {
+	if err := checker.ValidateCheck(body.Type, &body.Params); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -335,6 +392,7 @@ func (s *Server) CreateCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.publishCheckEvent(events.CheckCreated, check.ID)
 	writeJSON(w, http.StatusCreated, check)
 }
 
@@ -361,16 +419,19 @@ func (s *Server) RunChecks(w http.ResponseWriter, _ *http.Request) {
 			continue
 		}
 
-		resData := s.executeCheckForDomain(check, domain)
-		if resData == nil {
+		resData, err := checker.RunCheck(checker.CheckJob{Check: check, Domain: domain})
+		if err != nil {
+			log.Printf("check %d (%s) not executed: %v", check.ID, check.Type, err)
 			continue
 		}
 
-		res := s.createResult(check, *resData)
-		if err := s.ResultRepo.Add(res); err != nil {
+		res := s.createResult(check, resData)
+		id, err := s.ResultRepo.Add(res)
+		if err != nil {
 			log.Printf("failed to save result for check %d: %v", check.ID, err)
 			continue
 		}
+		res.ID = id
 
 		results = append(results, res)
 	}
@@ -381,72 +442,6 @@ func (s *Server) RunChecks(w http.ResponseWriter, _ *http.Request) {
 	})
 }
 
-func (s *Server) executeCheckForDomain(check models.Check, domain models.Domain) *checker.CheckResult {
-	timeout := s.getCheckTimeout(check)
-
-	switch check.Type {
-	case "http":
-		result := s.runHTTPCheckForDomain(domain, check, timeout)
-		return &result
-	case "icmp":
-		result := checker.RunICMPCheck(domain.Name, timeout)
-		return &result
-	case "tcp":
-		return s.runTCPCheckForDomain(domain, check, timeout)
-	case "udp":
-		return s.runUDPCheckForDomain(domain, check, timeout)
-	case "tls":
-		return s.runTLSCheckForDomain(domain, check, timeout)
-	default:
-		log.Printf("check type %s not yet executable, skipping check %d", check.Type, check.ID)
-		return nil
-	}
-}
-
-func (s *Server) getCheckTimeout(check models.Check) time.Duration {
-	timeout := 10 * time.Second
-	if check.Params.TimeoutMS > 0 {
-		timeout = time.Duration(check.Params.TimeoutMS) * time.Millisecond
-	}
-	return timeout
-}
-
-func (s *Server) runHTTPCheckForDomain(domain models.Domain, check models.Check, timeout time.Duration) checker.CheckResult {
-	fullURL := checker.BuildHTTPURL(domain.Name, check.Params)
-	method := checker.NormalizeHTTPMethod(check.Params.Method)
-	return checker.RunHTTPCheckWithMethodAndHeaders(fullURL, method, check.Params.Body, check.Params.Headers, timeout)
-}
-
-func (s *Server) runTCPCheckForDomain(domain models.Domain, check models.Check, timeout time.Duration) *checker.CheckResult {
-	port := check.Params.Port
-	if port <= 0 {
-		log.Printf("invalid port for TCP check %d", check.ID)
-		return nil
-	}
-	result := checker.RunTCPCheckWithPayload(domain.Name, port, check.Params.Payload, timeout)
-	return &result
-}
-
-func (s *Server) runUDPCheckForDomain(domain models.Domain, check models.Check, timeout time.Duration) *checker.CheckResult {
-	port := check.Params.Port
-	if port <= 0 {
-		log.Printf("invalid port for UDP check %d", check.ID)
-		return nil
-	}
-	result := checker.RunUDPCheck(domain.Name, port, check.Params.Payload, timeout)
-	return &result
-}
-
-func (s *Server) runTLSCheckForDomain(domain models.Domain, check models.Check, timeout time.Duration) *checker.CheckResult {
-	port := check.Params.Port
-	if port <= 0 {
-		log.Printf("invalid port for TLS check %d", check.ID)
-		return nil
-	}
-	result := checker.RunTLSCheck(domain.Name, port, timeout)
-	return &result
-}
-
 func (s *Server) createResult(check models.Check, resData checker.CheckResult) models.Result {
 	return models.Result{
 		CheckID:      check.ID,
@@ -455,6 +450,9 @@ func (s *Server) createResult(check models.Check, resData checker.CheckResult) m
 		DurationMS:   resData.DurationMS,
 		Outcome:      resData.Outcome,
 		ErrorMessage: resData.ErrorMessage,
+		Details:      resData.Details,
+		Timings:      resData.Timings,
+		Attempts:     resData.Attempts,
 		CreatedAt:    time.Now().Format(time.RFC3339),
 	}
 }
@@ -535,7 +533,7 @@ func (s *Server) GetResultsByCheckID(w http.ResponseWriter, r *http.Request) {
 
 // GetCheckStats godoc
 // @Summary Получить статистику проверки
-// @Description Возвращает агрегированную статистику: распределение по статусам и статистику latency
+// @Description Возвращает агрегированную статистику: распределение по статусам и статистику latency, для HTTP проверок — также по фазам запроса (dns, connect, tls, ttfb, transfer)
 // @Tags results
 // @Produce json
 // @Param id path int true "ID проверки"
@@ -574,6 +572,7 @@ func (s *Server) GetCheckStats(w http.ResponseWriter, r *http.Request) {
 		TotalResults:       stats.TotalResults,
 		StatusDistribution: stats.StatusDistribution,
 		LatencyStats:       stats.LatencyStats,
+		PhaseStats:         stats.PhaseStats,
 	}
 
 	writeJSON(w, http.StatusOK, response)
@@ -655,7 +654,7 @@ func (s *Server) GetCheckTimeIntervalData(w http.ResponseWriter, r *http.Request
 
 // GetNotificationSettings godoc
 // @Summary Получить настройки уведомлений
-// @Description Возвращает список всех настроек уведомлений
+// @Description Возвращает список всех настроек уведомлений. Секретные поля (token, webhook_url, secret, smtp_password, значения headers) замаскированы
 // @Tags notifications
 // @Produce json
 // @Success 200 {array} models.NotificationSettings
@@ -666,12 +665,16 @@ func (s *Server) GetNotificationSettings(w http.ResponseWriter, _ *http.Request)
 		writeError(w, http.StatusInternalServerError, "failed to get notification settings")
 		return
 	}
+	for i := range settings {
+		settings[i] = maskNotificationSecrets(settings[i])
+	}
 	writeJSON(w, http.StatusOK, settings)
 }
 
 // CreateNotificationSettings godoc
 // @Summary Создать настройки уведомлений
-// @Description Создает новую настройку уведомлений для Telegram или Slack
+// @Description Создает новую настройку уведомлений для Telegram, Slack, вебхука, email (SMTP), Discord, Microsoft Teams, Mattermost, PagerDuty или Opsgenie.
+// @Description Вебхук получает POST с JSON-событием (версия в поле version) и, если задан secret, подписью HMAC-SHA256 в заголовке X-DomainPulse-Signature
 // @Tags notifications
 // @Accept json
 // @Produce json
@@ -697,12 +700,13 @@ func (s *Server) CreateNotificationSettings(w http.ResponseWriter, r *http.Reque
 		writeError(w, http.StatusInternalServerError, "failed to add notification settings")
 		return
 	}
-	writeJSON(w, http.StatusCreated, result)
+	writeJSON(w, http.StatusCreated, maskNotificationSecrets(result))
 }
 
 // UpdateNotificationSettings godoc
 // @Summary Обновить настройки уведомлений
-// @Description Обновляет настройки уведомлений по ID
+// @Description Обновляет настройки уведомлений по ID. Замаскированное значение секретного поля,
+// @Description отправленное без изменений, сохраняет прежний секрет
 // @Tags notifications
 // @Accept json
 // @Produce json
@@ -720,7 +724,7 @@ func (s *Server) UpdateNotificationSettings(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	_, err = s.NotificationRepo.GetByID(id)
+	existing, err := s.NotificationRepo.GetByID(id)
 	if err != nil {
 		writeError(w, http.StatusNotFound, "notification settings not found")
 		return
@@ -731,6 +735,7 @@ func (s *Server) UpdateNotificationSettings(w http.ResponseWriter, r *http.Reque
 		writeError(w, http.StatusBadRequest, "invalid request body")
 		return
 	}
+	unmaskNotificationSecrets(&settings, existing)
 
 	if err := validateNotificationSettings(settings); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
@@ -748,7 +753,7 @@ func (s *Server) UpdateNotificationSettings(w http.ResponseWriter, r *http.Reque
 		writeError(w, http.StatusInternalServerError, "failed to get updated settings")
 		return
 	}
-	writeJSON(w, http.StatusOK, updated)
+	writeJSON(w, http.StatusOK, maskNotificationSecrets(updated))
 }
 
 // DeleteNotificationSettings godoc
@@ -848,7 +853,7 @@ func (s *Server) DisableNotificationSettings(w http.ResponseWriter, r *http.Requ
 
 // CreateCheckDirect godoc
 // @Summary Создать проверку
-// @Description Создает новую проверку (http, icmp, tcp, udp) с указанием domain_id в теле запроса
+// @Description Создает новую проверку (http, icmp, tcp, udp, tls, dns) с указанием domain_id в теле запроса
 // @Tags checks
 // @Accept json
 // @Produce json
@@ -883,7 +888,7 @@ func (s *Server) CreateCheckDirect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, ok := supportedCheckTypes[strings.ToLower(body.Type)]; !ok {
+	if _, ok := checker.Lookup(body.Type); !ok {
 		writeError(w, http.StatusBadRequest, "unsupported check type")
 		return
 	}
@@ -897,7 +902,7 @@ func (s *Server) CreateCheckDirect(w http.ResponseWriter, r *http.Request) {
 	}
 
 	body.Type = strings.ToLower(body.Type)
-	if err := validateCheckParams(body.Type, &body.Params); err != nil {
+	if err := checker.ValidateCheck(body.Type, &body.Params); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -913,6 +918,7 @@ func (s *Server) CreateCheckDirect(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.publishCheckEvent(events.CheckCreated, check.ID)
 	writeJSON(w, http.StatusCreated, check)
 }
 
@@ -954,7 +960,7 @@ func (s *Server) UpdateCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, ok := supportedCheckTypes[strings.ToLower(body.Type)]; !ok {
+	if _, ok := checker.Lookup(body.Type); !ok {
 		writeError(w, http.StatusBadRequest, "unsupported check type")
 		return
 	}
@@ -968,7 +974,7 @@ func (s *Server) UpdateCheck(w http.ResponseWriter, r *http.Request) {
 	}
 
 	body.Type = strings.ToLower(body.Type)
-	if err := validateCheckParams(body.Type, &body.Params); err != nil {
+	if err := checker.ValidateCheck(body.Type, &body.Params); err != nil {
 		writeError(w, http.StatusBadRequest, err.Error())
 		return
 	}
@@ -979,6 +985,7 @@ func (s *Server) UpdateCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	s.publishCheckEvent(events.CheckUpdated, checkID)
 	writeJSON(w, http.StatusOK, check)
 }
 
@@ -1009,6 +1016,7 @@ func (s *Server) EnableCheck(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to enable check")
 		return
 	}
+	s.publishCheckEvent(events.CheckEnabled, checkID)
 
 	writeJSON(w, http.StatusOK, map[string]any{"id": checkID, "enabled": true})
 }
@@ -1040,6 +1048,7 @@ func (s *Server) DisableCheck(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to disable check")
 		return
 	}
+	s.publishCheckEvent(events.CheckDisabled, checkID)
 
 	writeJSON(w, http.StatusOK, map[string]any{"id": checkID, "enabled": false})
 }
@@ -1071,6 +1080,10 @@ func (s *Server) DeleteCheck(w http.ResponseWriter, r *http.Request) {
 		writeError(w, http.StatusInternalServerError, "failed to delete check")
 		return
 	}
+	if err := s.DependencyRepo.DeleteByCheckID(checkID); err != nil {
+		log.Printf("failed to delete dependencies of check %d: %v", checkID, err)
+	}
+	s.publishCheckEvent(events.CheckDeleted, checkID)
 
 	writeJSON(w, http.StatusOK, map[string]any{"deleted": checkID})
 }
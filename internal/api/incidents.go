@@ -0,0 +1,257 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+	"github.com/MimoJanra/DomainPulse/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// --- Incident handlers ---
+
+func parseIncidentID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid incident id")
+	}
+	return id, nil
+}
+
+func validIncidentStatus(status string) bool {
+	switch status {
+	case "", storage.IncidentOpen, storage.IncidentAcknowledged, storage.IncidentResolved:
+		return true
+	default:
+		return false
+	}
+}
+
+func (s *Server) writeIncidentList(w http.ResponseWriter, r *http.Request, checkID *int) {
+	status := r.URL.Query().Get("status")
+	if !validIncidentStatus(status) {
+		writeError(w, http.StatusBadRequest, "status must be 'open', 'acknowledged' or 'resolved'")
+		return
+	}
+
+	page, pageSize := parsePagination(r, 50)
+
+	incidents, total, err := s.IncidentRepo.List(checkID, status, page, pageSize)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get incidents")
+		return
+	}
+
+	totalPages := (total + pageSize - 1) / pageSize
+	if totalPages == 0 {
+		totalPages = 1
+	}
+
+	writeJSON(w, http.StatusOK, models.IncidentsResponse{
+		Incidents:  incidents,
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		TotalPages: totalPages,
+	})
+}
+
+// GetIncidents godoc
+// @Summary Получить список инцидентов
+// @Description Возвращает инциденты (новые первыми) с фильтрацией по проверке и статусу и пагинацией
+// @Tags incidents
+// @Produce json
+// @Param check_id query int false "ID проверки"
+// @Param status query string false "Статус инцидента" Enums(open, acknowledged, resolved)
+// @Param page query int false "Номер страницы" default:"1"
+// @Param page_size query int false "Размер страницы" default:"50"
+// @Success 200 {object} models.IncidentsResponse
+// @Failure 400 {string} string "invalid check_id or status"
+// @Router /incidents [get]
+func (s *Server) GetIncidents(w http.ResponseWriter, r *http.Request) {
+	var checkID *int
+	if checkIDStr := r.URL.Query().Get("check_id"); checkIDStr != "" {
+		id, err := strconv.Atoi(checkIDStr)
+		if err != nil || id <= 0 {
+			writeError(w, http.StatusBadRequest, "invalid check_id")
+			return
+		}
+		checkID = &id
+	}
+	s.writeIncidentList(w, r, checkID)
+}
+
+// GetCheckIncidents godoc
+// @Summary Получить историю инцидентов проверки
+// @Description Возвращает инциденты проверки (новые первыми) с пагинацией
+// @Tags incidents
+// @Produce json
+// @Param id path int true "ID проверки"
+// @Param status query string false "Статус инцидента" Enums(open, acknowledged, resolved)
+// @Param page query int false "Номер страницы" default:"1"
+// @Param page_size query int false "Размер страницы" default:"50"
+// @Success 200 {object} models.IncidentsResponse
+// @Failure 400 {string} string "invalid check id or status"
+// @Failure 404 {string} string "check not found"
+// @Router /checks/{id}/incidents [get]
+func (s *Server) GetCheckIncidents(w http.ResponseWriter, r *http.Request) {
+	checkID, err := parseCheckID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.CheckRepo.GetByID(checkID); err != nil {
+		writeError(w, http.StatusNotFound, "check not found")
+		return
+	}
+
+	s.writeIncidentList(w, r, &checkID)
+}
+
+// GetIncident godoc
+// @Summary Получить инцидент
+// @Description Возвращает инцидент вместе с результатами проверок, которые к нему привели
+// @Tags incidents
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Success 200 {object} models.Incident
+// @Failure 400 {string} string "invalid incident id"
+// @Failure 404 {string} string "incident not found"
+// @Router /incidents/{id} [get]
+func (s *Server) GetIncident(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIncidentID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	incident, err := s.IncidentRepo.GetByID(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+
+	results, err := s.IncidentRepo.GetResults(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get incident results")
+		return
+	}
+	incident.Results = results
+
+	writeJSON(w, http.StatusOK, incident)
+}
+
+// AcknowledgeIncident godoc
+// @Summary Подтвердить инцидент
+// @Description Отмечает инцидент как взятый в работу: кто и с каким комментарием.
+// @Description Если acknowledged_by не указан, подставляется имя пользователя ключа API
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body models.IncidentAckRequest true "Кто подтверждает и комментарий"
+// @Success 200 {object} models.Incident
+// @Failure 400 {string} string "invalid incident id or request body"
+// @Failure 404 {string} string "incident not found"
+// @Failure 409 {string} string "incident already resolved"
+// @Router /incidents/{id}/acknowledge [post]
+func (s *Server) AcknowledgeIncident(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIncidentID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var body models.IncidentAckRequest
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if body.AcknowledgedBy == "" {
+		if user, ok := currentUser(r); ok {
+			body.AcknowledgedBy = user.Name
+		}
+	}
+	if body.AcknowledgedBy == "" {
+		writeError(w, http.StatusBadRequest, "acknowledged_by is required")
+		return
+	}
+
+	if _, err := s.IncidentRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+
+	err = s.IncidentRepo.Acknowledge(id, time.Now().Format(time.RFC3339), body.AcknowledgedBy, body.Note)
+	if errors.Is(err, storage.ErrIncidentResolved) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to acknowledge incident")
+		return
+	}
+
+	s.writeIncident(w, id)
+}
+
+// ResolveIncident godoc
+// @Summary Закрыть инцидент
+// @Description Закрывает инцидент вручную. Инциденты закрываются автоматически, когда проверка восстанавливается
+// @Tags incidents
+// @Accept json
+// @Produce json
+// @Param id path int true "ID инцидента"
+// @Param request body models.IncidentResolveRequest false "Комментарий"
+// @Success 200 {object} models.Incident
+// @Failure 400 {string} string "invalid incident id or request body"
+// @Failure 404 {string} string "incident not found"
+// @Failure 409 {string} string "incident already resolved"
+// @Router /incidents/{id}/resolve [post]
+func (s *Server) ResolveIncident(w http.ResponseWriter, r *http.Request) {
+	id, err := parseIncidentID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	var body models.IncidentResolveRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	if _, err := s.IncidentRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "incident not found")
+		return
+	}
+
+	err = s.IncidentRepo.Resolve(id, time.Now().Format(time.RFC3339), body.Note)
+	if errors.Is(err, storage.ErrIncidentResolved) {
+		writeError(w, http.StatusConflict, err.Error())
+		return
+	}
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to resolve incident")
+		return
+	}
+
+	s.writeIncident(w, id)
+}
+
+func (s *Server) writeIncident(w http.ResponseWriter, id int) {
+	incident, err := s.IncidentRepo.GetByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get updated incident")
+		return
+	}
+	writeJSON(w, http.StatusOK, incident)
+}
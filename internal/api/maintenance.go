@@ -0,0 +1,255 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+	"github.com/MimoJanra/DomainPulse/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// --- Maintenance window handlers ---
+
+func parseMaintenanceID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid maintenance window id")
+	}
+	return id, nil
+}
+
+// validateMaintenanceWindow проверяет окно и приводит его к каноническому виду:
+// режим по умолчанию — skip, поля чужой области видимости обнуляются.
+func (s *Server) validateMaintenanceWindow(mw *models.MaintenanceWindow) error {
+	switch mw.Scope {
+	case storage.MaintenanceScopeCheck:
+		if mw.CheckID <= 0 {
+			return errors.New("check_id is required for scope 'check'")
+		}
+		if _, err := s.CheckRepo.GetByID(mw.CheckID); err != nil {
+			return errors.New("check not found")
+		}
+		mw.DomainID = 0
+	case storage.MaintenanceScopeDomain:
+		if mw.DomainID <= 0 {
+			return errors.New("domain_id is required for scope 'domain'")
+		}
+		if _, err := s.DomainRepo.GetByID(mw.DomainID); err != nil {
+			return errors.New("domain not found")
+		}
+		mw.CheckID = 0
+	case storage.MaintenanceScopeGlobal:
+		mw.CheckID, mw.DomainID = 0, 0
+	default:
+		return errors.New("scope must be 'check', 'domain' or 'global'")
+	}
+
+	switch mw.Mode {
+	case "":
+		mw.Mode = storage.MaintenanceModeSkip
+	case storage.MaintenanceModeSkip, storage.MaintenanceModeMark:
+	default:
+		return errors.New("mode must be 'skip' or 'mark'")
+	}
+
+	if mw.Cron == "" {
+		mw.DurationMinutes = 0
+		mw.Timezone = ""
+	}
+
+	_, err := storage.NewMaintenanceSchedule(*mw)
+	return err
+}
+
+// GetMaintenanceWindows godoc
+// @Summary Получить окна обслуживания
+// @Description Возвращает все окна обслуживания
+// @Tags maintenance
+// @Produce json
+// @Success 200 {array} models.MaintenanceWindow
+// @Router /maintenance [get]
+func (s *Server) GetMaintenanceWindows(w http.ResponseWriter, _ *http.Request) {
+	windows, err := s.MaintenanceRepo.GetAll()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get maintenance windows")
+		return
+	}
+	writeJSON(w, http.StatusOK, windows)
+}
+
+// GetMaintenanceWindow godoc
+// @Summary Получить окно обслуживания
+// @Description Возвращает окно обслуживания по ID
+// @Tags maintenance
+// @Produce json
+// @Param id path int true "ID окна"
+// @Success 200 {object} models.MaintenanceWindow
+// @Failure 400 {string} string "invalid maintenance window id"
+// @Failure 404 {string} string "maintenance window not found"
+// @Router /maintenance/{id} [get]
+func (s *Server) GetMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := parseMaintenanceID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	mw, err := s.MaintenanceRepo.GetByID(id)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "maintenance window not found")
+		return
+	}
+	writeJSON(w, http.StatusOK, mw)
+}
+
+// CreateMaintenanceWindow godoc
+// @Summary Создать окно обслуживания
+// @Description Создаёт разовое (starts_at, ends_at) или повторяющееся (cron, duration_minutes) окно обслуживания
+// @Description для проверки, домена или всех проверок. Новое окно включено, если не указано enabled: false
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param window body models.MaintenanceWindow true "Окно обслуживания"
+// @Success 201 {object} models.MaintenanceWindow
+// @Failure 400 {string} string "invalid request body or window parameters"
+// @Router /maintenance [post]
+func (s *Server) CreateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	mw := models.MaintenanceWindow{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&mw); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := s.validateMaintenanceWindow(&mw); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	created, err := s.MaintenanceRepo.Add(mw)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to add maintenance window")
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// UpdateMaintenanceWindow godoc
+// @Summary Обновить окно обслуживания
+// @Description Заменяет параметры окна обслуживания
+// @Tags maintenance
+// @Accept json
+// @Produce json
+// @Param id path int true "ID окна"
+// @Param window body models.MaintenanceWindow true "Окно обслуживания"
+// @Success 200 {object} models.MaintenanceWindow
+// @Failure 400 {string} string "invalid request body or window parameters"
+// @Failure 404 {string} string "maintenance window not found"
+// @Router /maintenance/{id} [put]
+func (s *Server) UpdateMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := parseMaintenanceID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.MaintenanceRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "maintenance window not found")
+		return
+	}
+
+	mw := models.MaintenanceWindow{Enabled: true}
+	if err := json.NewDecoder(r.Body).Decode(&mw); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	if err := s.validateMaintenanceWindow(&mw); err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.MaintenanceRepo.Update(id, mw); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to update maintenance window")
+		return
+	}
+
+	updated, err := s.MaintenanceRepo.GetByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get updated maintenance window")
+		return
+	}
+	writeJSON(w, http.StatusOK, updated)
+}
+
+// DeleteMaintenanceWindow godoc
+// @Summary Удалить окно обслуживания
+// @Description Удаляет окно обслуживания по ID
+// @Tags maintenance
+// @Produce json
+// @Param id path int true "ID окна"
+// @Success 200 {object} map[string]int
+// @Failure 400 {string} string "invalid maintenance window id"
+// @Failure 404 {string} string "maintenance window not found"
+// @Router /maintenance/{id} [delete]
+func (s *Server) DeleteMaintenanceWindow(w http.ResponseWriter, r *http.Request) {
+	id, err := parseMaintenanceID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.MaintenanceRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "maintenance window not found")
+		return
+	}
+
+	if err := s.MaintenanceRepo.Delete(id); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete maintenance window")
+		return
+	}
+
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
+
+// GetCheckMaintenance godoc
+// @Summary Получить действующее окно обслуживания проверки
+// @Description Возвращает окно обслуживания, которое действует для проверки сейчас, или 204, если такого нет
+// @Tags maintenance
+// @Produce json
+// @Param id path int true "ID проверки"
+// @Success 200 {object} models.MaintenanceWindow
+// @Success 204 "no active maintenance window"
+// @Failure 400 {string} string "invalid check id"
+// @Failure 404 {string} string "check not found"
+// @Router /checks/{id}/maintenance [get]
+func (s *Server) GetCheckMaintenance(w http.ResponseWriter, r *http.Request) {
+	checkID, err := parseCheckID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	check, err := s.CheckRepo.GetByID(checkID)
+	if err != nil {
+		writeError(w, http.StatusNotFound, "check not found")
+		return
+	}
+
+	windows, err := s.MaintenanceRepo.GetForCheck(check.ID, check.DomainID)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get maintenance windows")
+		return
+	}
+
+	mw, active := storage.ActiveMaintenance(storage.NewMaintenanceSchedules(windows), time.Now())
+	if !active {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	writeJSON(w, http.StatusOK, mw)
+}
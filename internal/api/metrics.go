@@ -0,0 +1,129 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/MimoJanra/DomainPulse/internal/checker"
+	"github.com/MimoJanra/DomainPulse/internal/metrics"
+)
+
+// GetMetrics godoc
+// @Summary Метрики Prometheus
+// @Description Возвращает метрики в текстовом формате Prometheus: доступность, длительность последней проверки
+// @Description и число результатов по статусу и исходу для каждой проверки (метки check_id, domain, type),
+// @Description а также состояние очереди задач, серии ошибок проверок и отправку уведомлений
+// @Tags system
+// @Produce plain
+// @Success 200 {string} string "метрики в текстовом формате Prometheus"
+// @Failure 503 {string} string "scheduler is not running"
+// @Router /metrics [get]
+func (s *Server) GetMetrics(w http.ResponseWriter, _ *http.Request) {
+	if s.Scheduler == nil {
+		writeError(w, http.StatusServiceUnavailable, "scheduler is not running")
+		return
+	}
+
+	var buf bytes.Buffer
+	if err := writePoolMetrics(metrics.NewWriter(&buf), s.Scheduler.Metrics()); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to write metrics")
+		return
+	}
+
+	w.Header().Set("Content-Type", metrics.ContentType)
+	w.WriteHeader(http.StatusOK)
+	_, _ = w.Write(buf.Bytes())
+}
+
+func checkLabels(c checker.CheckMetricsSnapshot) []metrics.Label {
+	return []metrics.Label{
+		{Name: "check_id", Value: strconv.Itoa(c.CheckID)},
+		{Name: "domain", Value: c.Domain},
+		{Name: "type", Value: c.Type},
+	}
+}
+
+func writePoolMetrics(mw *metrics.Writer, m checker.PoolMetrics) error {
+	mw.Family("domainpulse_check_up", metrics.TypeGauge, "Whether the last result of the check was successful (1) or not (0).")
+	for _, c := range m.Checks {
+		if c.HasState {
+			up := 0.0
+			if c.Up {
+				up = 1
+			}
+			mw.Sample("domainpulse_check_up", up, checkLabels(c)...)
+		}
+	}
+
+	mw.Family("domainpulse_check_last_duration_seconds", metrics.TypeGauge, "Duration of the last check run.")
+	for _, c := range m.Checks {
+		if c.HasState {
+			mw.Sample("domainpulse_check_last_duration_seconds", float64(c.LastDurationMS)/1000, checkLabels(c)...)
+		}
+	}
+
+	mw.Family("domainpulse_check_last_result_timestamp_seconds", metrics.TypeGauge, "Unix time of the last check result.")
+	for _, c := range m.Checks {
+		if c.HasState {
+			mw.Sample("domainpulse_check_last_result_timestamp_seconds", float64(c.LastResultTime.Unix()), checkLabels(c)...)
+		}
+	}
+
+	mw.Family("domainpulse_check_error_streak", metrics.TypeGauge, "Number of consecutive error or timeout results of the check.")
+	for _, c := range m.Checks {
+		mw.Sample("domainpulse_check_error_streak", float64(c.ErrorStreak), checkLabels(c)...)
+	}
+
+	mw.Family("domainpulse_check_results_total", metrics.TypeCounter, "Check results recorded since start, by status and outcome.")
+	for _, c := range m.Checks {
+		keys := make([]checker.ResultKey, 0, len(c.Results))
+		for key := range c.Results {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if keys[i].Status != keys[j].Status {
+				return keys[i].Status < keys[j].Status
+			}
+			return keys[i].Outcome < keys[j].Outcome
+		})
+		for _, key := range keys {
+			labels := append(checkLabels(c),
+				metrics.Label{Name: "status", Value: key.Status},
+				metrics.Label{Name: "outcome", Value: key.Outcome})
+			mw.Sample("domainpulse_check_results_total", float64(c.Results[key]), labels...)
+		}
+	}
+
+	mw.Family("domainpulse_queue_depth", metrics.TypeGauge, "Number of check runs waiting in the job queue.")
+	mw.Sample("domainpulse_queue_depth", float64(m.Queue.Depth))
+	mw.Family("domainpulse_queue_capacity", metrics.TypeGauge, "Maximum number of check runs in the job queue.")
+	mw.Sample("domainpulse_queue_capacity", float64(m.Queue.Capacity))
+	mw.Family("domainpulse_workers", metrics.TypeGauge, "Number of workers executing checks.")
+	mw.Sample("domainpulse_workers", float64(m.Queue.Workers))
+	mw.Family("domainpulse_queue_enqueued_total", metrics.TypeCounter, "Check runs added to the job queue.")
+	mw.Sample("domainpulse_queue_enqueued_total", float64(m.Queue.Enqueued))
+	mw.Family("domainpulse_queue_coalesced_total", metrics.TypeCounter, "Check runs merged into a run of the same check already waiting in the queue.")
+	mw.Sample("domainpulse_queue_coalesced_total", float64(m.Queue.Coalesced))
+	mw.Family("domainpulse_queue_dropped_total", metrics.TypeCounter, "Check runs dropped because the job queue was full.")
+	mw.Sample("domainpulse_queue_dropped_total", float64(m.Queue.Dropped))
+
+	mw.Family("domainpulse_notifications_sent_total", metrics.TypeCounter, "Notifications sent, by channel type.")
+	writeChannelCounters(mw, "domainpulse_notifications_sent_total", m.NotificationsSent)
+	mw.Family("domainpulse_notification_failures_total", metrics.TypeCounter, "Notifications that failed to send, by channel type.")
+	writeChannelCounters(mw, "domainpulse_notification_failures_total", m.NotificationFailures)
+
+	return mw.Err()
+}
+
+func writeChannelCounters(mw *metrics.Writer, name string, counters map[string]uint64) {
+	channels := make([]string, 0, len(counters))
+	for channel := range counters {
+		channels = append(channels, channel)
+	}
+	sort.Strings(channels)
+	for _, channel := range channels {
+		mw.Sample(name, float64(counters[channel]), metrics.Label{Name: "channel", Value: channel})
+	}
+}
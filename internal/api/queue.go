@@ -0,0 +1,20 @@
+package api
+
+import "net/http"
+
+// GetQueueStats godoc
+// @Summary Получить состояние очереди проверок
+// @Description Возвращает глубину очереди задач, число объединённых дубликатов и запусков,
+// @Description отброшенных из-за переполнения (всего и по проверкам). Отброшенные запуски сохраняются в истории со статусом skipped
+// @Tags system
+// @Produce json
+// @Success 200 {object} models.QueueStats
+// @Failure 503 {string} string "scheduler is not running"
+// @Router /queue [get]
+func (s *Server) GetQueueStats(w http.ResponseWriter, _ *http.Request) {
+	if s.Scheduler == nil {
+		writeError(w, http.StatusServiceUnavailable, "scheduler is not running")
+		return
+	}
+	writeJSON(w, http.StatusOK, s.Scheduler.QueueStats())
+}
@@ -2,83 +2,161 @@ package api
 
 import (
 	"net/http"
+	"path/filepath"
 	"strconv"
 
 	"github.com/go-chi/chi/v5"
 	httpSwagger "github.com/swaggo/http-swagger"
 
 	_ "github.com/MimoJanra/DomainPulse/docs"
+	"github.com/MimoJanra/DomainPulse/internal/auth"
 )
 
 func SetupRouter(s *Server) http.Handler {
 	r := chi.NewRouter()
 
+	staticDir := s.StaticDir
+	if staticDir == "" {
+		staticDir = "web"
+	}
 	r.Get("/", func(w http.ResponseWriter, r *http.Request) {
-		http.ServeFile(w, r, "web/index.html")
+		http.ServeFile(w, r, filepath.Join(staticDir, "index.html"))
 	})
 	r.Get("/static/*", func(w http.ResponseWriter, r *http.Request) {
-		http.StripPrefix("/static/", http.FileServer(http.Dir("web/static"))).ServeHTTP(w, r)
+		http.StripPrefix("/static/", http.FileServer(http.Dir(filepath.Join(staticDir, "static")))).ServeHTTP(w, r)
 	})
 
 	r.Get("/swagger/*", httpSwagger.WrapHandler)
 
-	r.Get("/domains", s.GetDomains)
-	r.Post("/domains", s.CreateDomain)
-	r.Delete("/domains/{id}", func(w http.ResponseWriter, r *http.Request) {
-		id, err := strconv.Atoi(chi.URLParam(r, "id"))
-		if err != nil || id <= 0 {
-			http.Error(w, "invalid domain id", http.StatusBadRequest)
-			return
-		}
-		s.DeleteDomainByID(w, r, id)
-	})
-	r.Get("/domains/{id}/checks", func(w http.ResponseWriter, r *http.Request) {
-		s.GetCheck(w, r)
-	})
-	r.Post("/domains/{id}/checks", func(w http.ResponseWriter, r *http.Request) {
-		s.CreateCheck(w, r)
-	})
-	r.Post("/run-check", s.RunChecks)
-	r.Get("/results", s.GetResults)
-	r.Get("/checks/{id}/results", func(w http.ResponseWriter, r *http.Request) {
-		s.GetResultsByCheckID(w, r)
-	})
-	r.Get("/checks/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
-		s.GetCheckStats(w, r)
-	})
-	r.Get("/checks/{id}/intervals", func(w http.ResponseWriter, r *http.Request) {
-		s.GetCheckTimeIntervalData(w, r)
-	})
-	r.Get("/dashboard/recent", s.GetRecentDashboardData)
+	r.Group(func(r chi.Router) {
+		r.Use(s.authenticate)
 
-	r.Get("/checks", s.GetChecks)
-	r.Post("/checks", s.CreateCheckDirect)
-	r.Put("/checks/{id}", func(w http.ResponseWriter, r *http.Request) {
-		s.UpdateCheck(w, r)
-	})
-	r.Delete("/checks/{id}", func(w http.ResponseWriter, r *http.Request) {
-		s.DeleteCheck(w, r)
-	})
-	r.Post("/checks/{id}/enable", func(w http.ResponseWriter, r *http.Request) {
-		s.EnableCheck(w, r)
-	})
-	r.Post("/checks/{id}/disable", func(w http.ResponseWriter, r *http.Request) {
-		s.DisableCheck(w, r)
-	})
+		// Чтение доступно любой роли.
+		r.Get("/me", s.GetCurrentUser)
+		r.Get("/domains", s.GetDomains)
+		r.Get("/domains/{id}/checks", func(w http.ResponseWriter, r *http.Request) {
+			s.GetCheck(w, r)
+		})
+		r.Get("/results", s.GetResults)
+		r.Get("/checks/{id}/results", func(w http.ResponseWriter, r *http.Request) {
+			s.GetResultsByCheckID(w, r)
+		})
+		r.Get("/checks/{id}/stats", func(w http.ResponseWriter, r *http.Request) {
+			s.GetCheckStats(w, r)
+		})
+		r.Get("/checks/{id}/intervals", func(w http.ResponseWriter, r *http.Request) {
+			s.GetCheckTimeIntervalData(w, r)
+		})
+		r.Get("/dashboard/recent", s.GetRecentDashboardData)
+		r.Get("/checks", s.GetChecks)
+		r.Get("/checks/{id}/dependencies", func(w http.ResponseWriter, r *http.Request) {
+			s.GetCheckDependencies(w, r)
+		})
+		r.Get("/checks/{id}/maintenance", func(w http.ResponseWriter, r *http.Request) {
+			s.GetCheckMaintenance(w, r)
+		})
+		r.Get("/maintenance", s.GetMaintenanceWindows)
+		r.Get("/maintenance/{id}", func(w http.ResponseWriter, r *http.Request) {
+			s.GetMaintenanceWindow(w, r)
+		})
+		r.Get("/queue", s.GetQueueStats)
+		r.Get("/metrics", s.GetMetrics)
+		r.Get("/checks/{id}/incidents", func(w http.ResponseWriter, r *http.Request) {
+			s.GetCheckIncidents(w, r)
+		})
+		r.Get("/incidents", s.GetIncidents)
+		r.Get("/incidents/{id}", func(w http.ResponseWriter, r *http.Request) {
+			s.GetIncident(w, r)
+		})
 
-	r.Get("/notifications", s.GetNotificationSettings)
-	r.Post("/notifications", s.CreateNotificationSettings)
-	r.Put("/notifications/{id}", func(w http.ResponseWriter, r *http.Request) {
-		s.UpdateNotificationSettings(w, r)
-	})
-	r.Delete("/notifications/{id}", func(w http.ResponseWriter, r *http.Request) {
-		s.DeleteNotificationSettings(w, r)
-	})
-	r.Post("/notifications/{id}/enable", func(w http.ResponseWriter, r *http.Request) {
-		s.EnableNotificationSettings(w, r)
-	})
-	r.Post("/notifications/{id}/disable", func(w http.ResponseWriter, r *http.Request) {
-		s.DisableNotificationSettings(w, r)
+		// Изменения — editor и admin.
+		r.Group(func(r chi.Router) {
+			r.Use(requireRole(auth.RoleEditor))
+
+			r.Post("/domains", s.CreateDomain)
+			r.Delete("/domains/{id}", func(w http.ResponseWriter, r *http.Request) {
+				id, err := strconv.Atoi(chi.URLParam(r, "id"))
+				if err != nil || id <= 0 {
+					http.Error(w, "invalid domain id", http.StatusBadRequest)
+					return
+				}
+				s.DeleteDomainByID(w, r, id)
+			})
+			r.Post("/domains/{id}/checks", func(w http.ResponseWriter, r *http.Request) {
+				s.CreateCheck(w, r)
+			})
+			r.Post("/run-check", s.RunChecks)
+
+			r.Post("/checks", s.CreateCheckDirect)
+			r.Put("/checks/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.UpdateCheck(w, r)
+			})
+			r.Delete("/checks/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.DeleteCheck(w, r)
+			})
+			r.Post("/checks/{id}/enable", func(w http.ResponseWriter, r *http.Request) {
+				s.EnableCheck(w, r)
+			})
+			r.Post("/checks/{id}/disable", func(w http.ResponseWriter, r *http.Request) {
+				s.DisableCheck(w, r)
+			})
+			r.Put("/checks/{id}/dependencies", func(w http.ResponseWriter, r *http.Request) {
+				s.SetCheckDependencies(w, r)
+			})
+
+			r.Post("/maintenance", s.CreateMaintenanceWindow)
+			r.Put("/maintenance/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.UpdateMaintenanceWindow(w, r)
+			})
+			r.Delete("/maintenance/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.DeleteMaintenanceWindow(w, r)
+			})
+
+			r.Post("/incidents/{id}/acknowledge", func(w http.ResponseWriter, r *http.Request) {
+				s.AcknowledgeIncident(w, r)
+			})
+			r.Post("/incidents/{id}/resolve", func(w http.ResponseWriter, r *http.Request) {
+				s.ResolveIncident(w, r)
+			})
+		})
+
+		// Уведомления (содержат токены), пользователи и ключи — только admin.
+		r.Group(func(r chi.Router) {
+			r.Use(requireRole(auth.RoleAdmin))
+
+			r.Get("/notifications", s.GetNotificationSettings)
+			r.Post("/notifications", s.CreateNotificationSettings)
+			r.Put("/notifications/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.UpdateNotificationSettings(w, r)
+			})
+			r.Delete("/notifications/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.DeleteNotificationSettings(w, r)
+			})
+			r.Post("/notifications/{id}/enable", func(w http.ResponseWriter, r *http.Request) {
+				s.EnableNotificationSettings(w, r)
+			})
+			r.Post("/notifications/{id}/disable", func(w http.ResponseWriter, r *http.Request) {
+				s.DisableNotificationSettings(w, r)
+			})
+
+			r.Get("/users", s.GetUsers)
+			r.Post("/users", s.CreateUser)
+			r.Put("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.UpdateUser(w, r)
+			})
+			r.Delete("/users/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.DeleteUser(w, r)
+			})
+			r.Get("/users/{id}/keys", func(w http.ResponseWriter, r *http.Request) {
+				s.GetUserKeys(w, r)
+			})
+			r.Post("/users/{id}/keys", func(w http.ResponseWriter, r *http.Request) {
+				s.CreateUserKey(w, r)
+			})
+			r.Delete("/keys/{id}", func(w http.ResponseWriter, r *http.Request) {
+				s.DeleteAPIKey(w, r)
+			})
+		})
 	})
 
 	return r
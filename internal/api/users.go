@@ -0,0 +1,317 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/MimoJanra/DomainPulse/internal/auth"
+	"github.com/MimoJanra/DomainPulse/internal/models"
+	"github.com/MimoJanra/DomainPulse/internal/storage"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// --- User and API key handlers ---
+
+func parseUserID(r *http.Request) (int, error) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		return 0, errors.New("invalid user id")
+	}
+	return id, nil
+}
+
+func decodeUserRequest(r *http.Request) (models.UserRequest, error) {
+	var req models.UserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		return req, errors.New("invalid request body")
+	}
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return req, errors.New("name is required")
+	}
+	if !auth.ValidRole(req.Role) {
+		return req, errors.New("role must be 'viewer', 'editor' or 'admin'")
+	}
+	return req, nil
+}
+
+func writeUserError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, storage.ErrUserExists), errors.Is(err, storage.ErrLastAdmin):
+		writeError(w, http.StatusConflict, err.Error())
+	default:
+		writeError(w, http.StatusInternalServerError, "failed to "+action+" user")
+	}
+}
+
+// GetCurrentUser godoc
+// @Summary Текущий пользователь
+// @Description Возвращает пользователя, которому принадлежит ключ API запроса
+// @Tags users
+// @Produce json
+// @Success 200 {object} models.User
+// @Failure 401 {string} string "api key required"
+// @Router /me [get]
+func (s *Server) GetCurrentUser(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(r)
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "api key required")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// GetUsers godoc
+// @Summary Получить пользователей
+// @Description Возвращает всех пользователей API (только admin)
+// @Tags users
+// @Produce json
+// @Success 200 {array} models.User
+// @Router /users [get]
+func (s *Server) GetUsers(w http.ResponseWriter, _ *http.Request) {
+	users, err := s.UserRepo.GetAll()
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get users")
+		return
+	}
+	writeJSON(w, http.StatusOK, users)
+}
+
+// CreateUser godoc
+// @Summary Создать пользователя
+// @Description Создаёт пользователя с ролью viewer, editor или admin (только admin). Ключи создаются отдельно
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body models.UserRequest true "Пользователь"
+// @Success 201 {object} models.User
+// @Failure 400 {string} string "invalid request body, name or role"
+// @Failure 409 {string} string "user already exists"
+// @Router /users [post]
+func (s *Server) CreateUser(w http.ResponseWriter, r *http.Request) {
+	req, err := decodeUserRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	user, err := s.UserRepo.Add(req.Name, req.Role)
+	if err != nil {
+		writeUserError(w, err, "add")
+		return
+	}
+	writeJSON(w, http.StatusCreated, user)
+}
+
+// UpdateUser godoc
+// @Summary Обновить пользователя
+// @Description Меняет имя и роль пользователя (только admin). Роль единственного администратора понизить нельзя
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param user body models.UserRequest true "Пользователь"
+// @Success 200 {object} models.User
+// @Failure 400 {string} string "invalid request body, name or role"
+// @Failure 404 {string} string "user not found"
+// @Failure 409 {string} string "user already exists or cannot remove the last admin"
+// @Router /users/{id} [put]
+func (s *Server) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUserID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.UserRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	req, err := decodeUserRequest(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if err := s.UserRepo.Update(id, req.Name, req.Role); err != nil {
+		writeUserError(w, err, "update")
+		return
+	}
+
+	user, err := s.UserRepo.GetByID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get updated user")
+		return
+	}
+	writeJSON(w, http.StatusOK, user)
+}
+
+// DeleteUser godoc
+// @Summary Удалить пользователя
+// @Description Удаляет пользователя и все его ключи (только admin). Единственного администратора удалить нельзя
+// @Tags users
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {object} map[string]int
+// @Failure 400 {string} string "invalid user id"
+// @Failure 404 {string} string "user not found"
+// @Failure 409 {string} string "cannot remove the last admin"
+// @Router /users/{id} [delete]
+func (s *Server) DeleteUser(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUserID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.UserRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	if err := s.UserRepo.Delete(id); err != nil {
+		writeUserError(w, err, "delete")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
+
+// GetUserKeys godoc
+// @Summary Получить ключи пользователя
+// @Description Возвращает ключи API пользователя без самих ключей (только admin)
+// @Tags users
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Success 200 {array} models.APIKey
+// @Failure 400 {string} string "invalid user id"
+// @Failure 404 {string} string "user not found"
+// @Router /users/{id}/keys [get]
+func (s *Server) GetUserKeys(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUserID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.UserRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	keys, err := s.APIKeyRepo.GetByUserID(id)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to get api keys")
+		return
+	}
+	writeJSON(w, http.StatusOK, keys)
+}
+
+// CreateUserKey godoc
+// @Summary Создать ключ API
+// @Description Создаёт ключ API пользователя (только admin). Ключ возвращается только в этом ответе,
+// @Description в БД хранится его хеш. Ключ передаётся в заголовке X-API-Key или Authorization: Bearer
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path int true "ID пользователя"
+// @Param key body models.APIKeyRequest false "Ключ"
+// @Success 201 {object} models.CreatedAPIKey
+// @Failure 400 {string} string "invalid user id or request body"
+// @Failure 404 {string} string "user not found"
+// @Router /users/{id}/keys [post]
+func (s *Server) CreateUserKey(w http.ResponseWriter, r *http.Request) {
+	id, err := parseUserID(r)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	if _, err := s.UserRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "user not found")
+		return
+	}
+
+	var req models.APIKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(w, http.StatusBadRequest, "invalid request body")
+			return
+		}
+	}
+
+	created, err := CreateAPIKey(s.APIKeyRepo, id, strings.TrimSpace(req.Name))
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to create api key")
+		return
+	}
+	writeJSON(w, http.StatusCreated, created)
+}
+
+// DeleteAPIKey godoc
+// @Summary Отозвать ключ API
+// @Description Удаляет ключ API (только admin); запросы с ним сразу перестают проходить
+// @Tags users
+// @Produce json
+// @Param id path int true "ID ключа"
+// @Success 200 {object} map[string]int
+// @Failure 400 {string} string "invalid api key id"
+// @Failure 404 {string} string "api key not found"
+// @Router /keys/{id} [delete]
+func (s *Server) DeleteAPIKey(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil || id <= 0 {
+		writeError(w, http.StatusBadRequest, "invalid api key id")
+		return
+	}
+
+	if _, err := s.APIKeyRepo.GetByID(id); err != nil {
+		writeError(w, http.StatusNotFound, "api key not found")
+		return
+	}
+
+	if err := s.APIKeyRepo.Delete(id); err != nil {
+		writeError(w, http.StatusInternalServerError, "failed to delete api key")
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"deleted": id})
+}
+
+// CreateAPIKey генерирует ключ пользователя и сохраняет его хеш.
+func CreateAPIKey(repo *storage.APIKeyRepo, userID int, name string) (models.CreatedAPIKey, error) {
+	key, prefix, err := auth.GenerateKey()
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	stored, err := repo.Add(userID, name, prefix, auth.HashKey(key))
+	if err != nil {
+		return models.CreatedAPIKey{}, err
+	}
+	return models.CreatedAPIKey{APIKey: stored, Key: key}, nil
+}
+
+// BootstrapAdmin при первом запуске, пока нет ни одного пользователя, создаёт администратора admin
+// с ключом API. Возвращает ключ или пустую строку, если пользователи уже есть.
+func BootstrapAdmin(users *storage.UserRepo, keys *storage.APIKeyRepo) (string, error) {
+	count, err := users.Count()
+	if err != nil {
+		return "", err
+	}
+	if count > 0 {
+		return "", nil
+	}
+
+	admin, err := users.Add("admin", auth.RoleAdmin)
+	if err != nil {
+		return "", err
+	}
+	created, err := CreateAPIKey(keys, admin.ID, "bootstrap")
+	if err != nil {
+		return "", err
+	}
+	return created.Key, nil
+}
@@ -0,0 +1,58 @@
+// Package auth — роли пользователей API и ключи API. В хранилище попадает только SHA-256 хеш ключа:
+// ключ содержит 256 случайных бит, поэтому медленное хеширование не требуется.
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+)
+
+// Роли по возрастанию прав: viewer читает данные, editor также изменяет домены, проверки,
+// окна обслуживания и инциденты, admin также управляет уведомлениями, пользователями и ключами.
+const (
+	RoleViewer = "viewer"
+	RoleEditor = "editor"
+	RoleAdmin  = "admin"
+)
+
+var roleRank = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// keyPrefix отличает ключи DomainPulse от других секретов (например, при поиске утечек).
+const keyPrefix = "dp_"
+
+// displayPrefixLen — длина начала ключа, которое хранится открыто, чтобы ключи можно было различать.
+const displayPrefixLen = len(keyPrefix) + 8
+
+func ValidRole(role string) bool {
+	_, ok := roleRank[role]
+	return ok
+}
+
+// Allows сообщает, достаточно ли роли role для действия, требующего роль required.
+func Allows(role, required string) bool {
+	have, ok := roleRank[role]
+	return ok && have >= roleRank[required]
+}
+
+// GenerateKey создаёт новый ключ и возвращает его вместе с префиксом для отображения.
+func GenerateKey() (key, prefix string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate api key: %w", err)
+	}
+	key = keyPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return key, key[:displayPrefixLen], nil
+}
+
+// HashKey возвращает хеш ключа, по которому ключ ищется в хранилище.
+func HashKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,156 @@
+package checker
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+	"github.com/MimoJanra/DomainPulse/internal/storage"
+)
+
+const (
+	StateUp   = "up"
+	StateDown = "down"
+)
+
+// События смены состояния, по которым отправляются уведомления.
+const (
+	AlertEventDown      = "down"
+	AlertEventRecovered = "recovered"
+	AlertEventReminder  = "reminder"
+)
+
+// stateTracker хранит состояние up/down каждой проверки (в памяти и в check_states)
+// и определяет, когда результат меняет состояние.
+type stateTracker struct {
+	mu     sync.Mutex
+	repo   *storage.CheckStateRepo
+	states map[int]models.CheckState
+	// slow — проверки, для которых уже отправлено уведомление о медленном ответе, по ID настроек.
+	slow map[int]map[int]bool
+	// lastDown — был ли последний результат проверки неуспешным, включая подавленные сбои.
+	lastDown map[int]bool
+}
+
+func newStateTracker(repo *storage.CheckStateRepo) *stateTracker {
+	return &stateTracker{
+		repo:     repo,
+		states:   make(map[int]models.CheckState),
+		slow:     make(map[int]map[int]bool),
+		lastDown: make(map[int]bool),
+	}
+}
+
+func isDownStatus(status string) bool {
+	return status != "success"
+}
+
+// observe учитывает результат и возвращает событие для уведомления ("" — уведомлять не нужно)
+// и время начала недоступности (для recovered — только что закончившейся).
+// Первый результат проверки считается переходом, только если она сразу недоступна.
+func (t *stateTracker) observe(checkID int, status string, renotify time.Duration, now time.Time) (event, downSince string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.lastDown[checkID] = isDownStatus(status)
+
+	prev, known := t.load(checkID)
+	next := prev
+	if !known {
+		next = models.CheckState{CheckID: checkID, State: StateUp, Since: now.Format(time.RFC3339)}
+	}
+
+	if isDownStatus(status) {
+		next.ConsecutiveFailures++
+		switch {
+		case next.State != StateDown:
+			next.State = StateDown
+			next.Since = now.Format(time.RFC3339)
+			event = AlertEventDown
+		case renotify > 0 && reminderDue(next.LastNotifiedAt, renotify, now):
+			event = AlertEventReminder
+		}
+	} else {
+		next.ConsecutiveFailures = 0
+		if next.State != StateUp {
+			downSince = next.Since
+			next.State = StateUp
+			next.Since = now.Format(time.RFC3339)
+			event = AlertEventRecovered
+		}
+	}
+	if event != "" {
+		next.LastNotifiedAt = now.Format(time.RFC3339)
+	}
+
+	if !known || next != prev {
+		t.states[checkID] = next
+		if err := t.repo.Save(next); err != nil {
+			log.Printf("failed to save state for check %d: %v", checkID, err)
+		}
+	}
+	if next.State == StateDown {
+		downSince = next.Since
+	}
+	return event, downSince
+}
+
+// markSuppressed учитывает подавленный сбой: состояние проверки и уведомления не меняются,
+// но зависимые от неё проверки тоже считаются затронутыми сбоем.
+func (t *stateTracker) markSuppressed(checkID int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.lastDown[checkID] = true
+}
+
+// isDown сообщает, недоступна ли проверка по последнему результату. До первого результата
+// после запуска используется сохранённое состояние.
+func (t *stateTracker) isDown(checkID int) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if down, ok := t.lastDown[checkID]; ok {
+		return down
+	}
+	state, found := t.load(checkID)
+	return found && state.State == StateDown
+}
+
+func (t *stateTracker) load(checkID int) (models.CheckState, bool) {
+	if state, ok := t.states[checkID]; ok {
+		return state, true
+	}
+	state, found, err := t.repo.Get(checkID)
+	if err != nil {
+		log.Printf("failed to load state for check %d: %v", checkID, err)
+		return models.CheckState{}, false
+	}
+	if found {
+		t.states[checkID] = state
+	}
+	return state, found
+}
+
+func reminderDue(lastNotifiedAt string, interval time.Duration, now time.Time) bool {
+	last, err := time.Parse(time.RFC3339, lastNotifiedAt)
+	if err != nil {
+		return true
+	}
+	return now.Sub(last) >= interval
+}
+
+// slowChanged отмечает, превышен ли порог медленного ответа для канала уведомлений,
+// и возвращает true только при переходе в состояние "медленно".
+func (t *stateTracker) slowChanged(checkID, settingsID int, slow bool) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	bySettings := t.slow[checkID]
+	if bySettings == nil {
+		bySettings = make(map[int]bool)
+		t.slow[checkID] = bySettings
+	}
+	wasSlow := bySettings[settingsID]
+	bySettings[settingsID] = slow
+	return slow && !wasSlow
+}
@@ -0,0 +1,466 @@
+package checker
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+	"golang.org/x/net/dns/dnsmessage"
+)
+
+const typeCAA = dnsmessage.Type(257)
+
+var dnsRecordTypes = map[string]dnsmessage.Type{
+	"A":     dnsmessage.TypeA,
+	"AAAA":  dnsmessage.TypeAAAA,
+	"CNAME": dnsmessage.TypeCNAME,
+	"MX":    dnsmessage.TypeMX,
+	"TXT":   dnsmessage.TypeTXT,
+	"NS":    dnsmessage.TypeNS,
+	"SOA":   dnsmessage.TypeSOA,
+	"CAA":   typeCAA,
+	"SRV":   dnsmessage.TypeSRV,
+}
+
+const resolvConfPath = "/etc/resolv.conf"
+
+type dnsChecker struct{}
+
+func (dnsChecker) Type() string { return "dns" }
+
+func (dnsChecker) Validate(params *models.CheckParams) error {
+	params.RecordType = strings.ToUpper(strings.TrimSpace(params.RecordType))
+	if params.RecordType == "" {
+		params.RecordType = "A"
+	}
+	if _, ok := dnsRecordTypes[params.RecordType]; !ok {
+		return fmt.Errorf("unsupported record_type %q (supported: A, AAAA, CNAME, MX, TXT, NS, SOA, CAA, SRV)", params.RecordType)
+	}
+	if params.Resolver != "" {
+		resolver, err := normalizeResolverAddr(params.Resolver)
+		if err != nil {
+			return err
+		}
+		params.Resolver = resolver
+	}
+	if params.MinRecords < 0 {
+		return errors.New("min_records must be >= 0")
+	}
+	return nil
+}
+
+func (dnsChecker) Run(job CheckJob, timeout time.Duration) (CheckResult, error) {
+	params := job.Check.Params
+	recordType := strings.ToUpper(params.RecordType)
+	if recordType == "" {
+		recordType = "A"
+	}
+
+	resolver := params.Resolver
+	if resolver == "" {
+		var err error
+		resolver, err = systemResolver()
+		if err != nil {
+			return CheckResult{}, err
+		}
+	}
+
+	name := params.QueryName
+	if name == "" {
+		name = job.Domain.Name
+	}
+
+	return RunDNSCheck(name, recordType, resolver, params.ExpectedValues, params.MinRecords, timeout), nil
+}
+
+func normalizeResolverAddr(addr string) (string, error) {
+	addr = strings.TrimSpace(addr)
+	if ip := net.ParseIP(strings.Trim(addr, "[]")); ip != nil {
+		return net.JoinHostPort(ip.String(), "53"), nil
+	}
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil {
+		return "", fmt.Errorf("invalid resolver address %q: %v", addr, err)
+	}
+	if p, err := strconv.Atoi(port); err != nil || p <= 0 || p > 65535 {
+		return "", fmt.Errorf("invalid resolver port %q", port)
+	}
+	return net.JoinHostPort(host, port), nil
+}
+
+func systemResolver() (string, error) {
+	f, err := os.Open(resolvConfPath)
+	if err != nil {
+		return "", fmt.Errorf("resolver is not set and system resolver is unavailable: %v", err)
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) >= 2 && fields[0] == "nameserver" {
+			return normalizeResolverAddr(fields[1])
+		}
+	}
+	return "", errors.New("resolver is not set and no nameserver found in " + resolvConfPath)
+}
+
+func RunDNSCheck(name, recordType, resolver string, expected []string, minRecords int, timeout time.Duration) CheckResult {
+	start := time.Now()
+	qtype := dnsRecordTypes[recordType]
+
+	details := map[string]any{
+		"resolver":    resolver,
+		"record_type": recordType,
+		"query_name":  name,
+	}
+
+	query, id, err := buildDNSQuery(name, qtype)
+	if err != nil {
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   int(time.Since(start).Milliseconds()),
+			Outcome:      "error",
+			ErrorMessage: fmt.Sprintf("failed to build DNS query: %v", err),
+			Details:      details,
+		}
+	}
+
+	resp, rtt, err := exchangeDNS(query, id, resolver, timeout)
+	duration := int(time.Since(start).Milliseconds())
+	if err != nil {
+		status, outcome := "error", "error"
+		if isNetworkTimeout(err) {
+			status, outcome = "timeout", "timeout"
+		}
+		return CheckResult{
+			Status:       status,
+			DurationMS:   duration,
+			Outcome:      outcome,
+			ErrorMessage: fmt.Sprintf("DNS query failed: %v", err),
+			Details:      details,
+		}
+	}
+
+	rttMS := int(rtt.Milliseconds())
+	details["rtt_ms"] = rttMS
+
+	rcode, answers, err := parseDNSAnswers(resp, qtype)
+	details["rcode"] = strings.TrimPrefix(rcode.String(), "RCode")
+	if err != nil {
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   rttMS,
+			Outcome:      "error",
+			ErrorMessage: fmt.Sprintf("failed to parse DNS response: %v", err),
+			Details:      details,
+		}
+	}
+	details["answers"] = answers
+
+	if rcode != dnsmessage.RCodeSuccess {
+		outcome := "dns_error"
+		switch rcode {
+		case dnsmessage.RCodeNameError:
+			outcome = "nxdomain"
+		case dnsmessage.RCodeServerFailure:
+			outcome = "servfail"
+		}
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   rttMS,
+			Outcome:      outcome,
+			ErrorMessage: fmt.Sprintf("resolver returned %s for %s %s", details["rcode"], recordType, name),
+			Details:      details,
+		}
+	}
+
+	if minRecords <= 0 {
+		minRecords = 1
+	}
+	if len(answers) < minRecords {
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   rttMS,
+			Outcome:      "too_few_records",
+			ErrorMessage: fmt.Sprintf("expected at least %d %s record(s), got %d", minRecords, recordType, len(answers)),
+			Details:      details,
+		}
+	}
+
+	if missing := missingDNSValues(expected, answers); len(missing) > 0 {
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   rttMS,
+			Outcome:      "answer_mismatch",
+			ErrorMessage: fmt.Sprintf("expected %s values not found: %s (got: %s)", recordType, strings.Join(missing, ", "), strings.Join(answers, ", ")),
+			Details:      details,
+		}
+	}
+
+	return CheckResult{
+		Status:       "success",
+		DurationMS:   rttMS,
+		Outcome:      "success",
+		ErrorMessage: "",
+		Details:      details,
+	}
+}
+
+func buildDNSQuery(name string, qtype dnsmessage.Type) ([]byte, uint16, error) {
+	if !strings.HasSuffix(name, ".") {
+		name += "."
+	}
+	qname, err := dnsmessage.NewName(name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var idBuf [2]byte
+	if _, err := rand.Read(idBuf[:]); err != nil {
+		return nil, 0, err
+	}
+	id := binary.BigEndian.Uint16(idBuf[:])
+
+	b := dnsmessage.NewBuilder(make([]byte, 0, 512), dnsmessage.Header{ID: id, RecursionDesired: true})
+	b.EnableCompression()
+	if err := b.StartQuestions(); err != nil {
+		return nil, 0, err
+	}
+	if err := b.Question(dnsmessage.Question{Name: qname, Type: qtype, Class: dnsmessage.ClassINET}); err != nil {
+		return nil, 0, err
+	}
+	if err := b.StartAdditionals(); err != nil {
+		return nil, 0, err
+	}
+	var opt dnsmessage.ResourceHeader
+	if err := opt.SetEDNS0(1232, dnsmessage.RCodeSuccess, false); err != nil {
+		return nil, 0, err
+	}
+	if err := b.OPTResource(opt, dnsmessage.OPTResource{}); err != nil {
+		return nil, 0, err
+	}
+	msg, err := b.Finish()
+	return msg, id, err
+}
+
+// exchangeDNS отправляет запрос по UDP и повторяет его по TCP, если ответ усечён.
+// Возвращает ответ и RTT последнего обмена.
+func exchangeDNS(query []byte, id uint16, resolver string, timeout time.Duration) ([]byte, time.Duration, error) {
+	resp, rtt, err := exchangeDNSUDP(query, id, resolver, timeout)
+	if err != nil {
+		return nil, rtt, err
+	}
+
+	var p dnsmessage.Parser
+	h, err := p.Start(resp)
+	if err != nil {
+		return nil, rtt, err
+	}
+	if !h.Truncated {
+		return resp, rtt, nil
+	}
+	return exchangeDNSTCP(query, id, resolver, timeout)
+}
+
+func exchangeDNSUDP(query []byte, id uint16, resolver string, timeout time.Duration) ([]byte, time.Duration, error) {
+	conn, err := net.DialTimeout("udp", resolver, timeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, 0, err
+	}
+
+	start := time.Now()
+	if _, err := conn.Write(query); err != nil {
+		return nil, 0, err
+	}
+
+	buf := make([]byte, 65535)
+	for {
+		n, err := conn.Read(buf)
+		if err != nil {
+			return nil, time.Since(start), err
+		}
+		if n >= 2 && binary.BigEndian.Uint16(buf[:2]) == id {
+			return buf[:n], time.Since(start), nil
+		}
+	}
+}
+
+func exchangeDNSTCP(query []byte, id uint16, resolver string, timeout time.Duration) ([]byte, time.Duration, error) {
+	conn, err := net.DialTimeout("tcp", resolver, timeout)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer conn.Close()
+
+	if err := conn.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, 0, err
+	}
+
+	start := time.Now()
+	framed := make([]byte, 2+len(query))
+	binary.BigEndian.PutUint16(framed, uint16(len(query)))
+	copy(framed[2:], query)
+	if _, err := conn.Write(framed); err != nil {
+		return nil, 0, err
+	}
+
+	var lenBuf [2]byte
+	if _, err := io.ReadFull(conn, lenBuf[:]); err != nil {
+		return nil, time.Since(start), err
+	}
+	resp := make([]byte, binary.BigEndian.Uint16(lenBuf[:]))
+	if _, err := io.ReadFull(conn, resp); err != nil {
+		return nil, time.Since(start), err
+	}
+	rtt := time.Since(start)
+
+	if len(resp) < 2 || binary.BigEndian.Uint16(resp[:2]) != id {
+		return nil, rtt, errors.New("DNS response id mismatch")
+	}
+	return resp, rtt, nil
+}
+
+func parseDNSAnswers(msg []byte, qtype dnsmessage.Type) (dnsmessage.RCode, []string, error) {
+	var p dnsmessage.Parser
+	h, err := p.Start(msg)
+	if err != nil {
+		return 0, nil, err
+	}
+	if err := p.SkipAllQuestions(); err != nil {
+		return h.RCode, nil, err
+	}
+
+	answers := []string{}
+	for {
+		rh, err := p.AnswerHeader()
+		if errors.Is(err, dnsmessage.ErrSectionDone) {
+			break
+		}
+		if err != nil {
+			return h.RCode, nil, err
+		}
+		if rh.Type != qtype {
+			if err := p.SkipAnswer(); err != nil {
+				return h.RCode, nil, err
+			}
+			continue
+		}
+		value, err := formatDNSAnswer(&p, rh.Type)
+		if err != nil {
+			return h.RCode, nil, err
+		}
+		answers = append(answers, value)
+	}
+	return h.RCode, answers, nil
+}
+
+func formatDNSAnswer(p *dnsmessage.Parser, t dnsmessage.Type) (string, error) {
+	switch t {
+	case dnsmessage.TypeA:
+		r, err := p.AResource()
+		if err != nil {
+			return "", err
+		}
+		return net.IP(r.A[:]).String(), nil
+	case dnsmessage.TypeAAAA:
+		r, err := p.AAAAResource()
+		if err != nil {
+			return "", err
+		}
+		return net.IP(r.AAAA[:]).String(), nil
+	case dnsmessage.TypeCNAME:
+		r, err := p.CNAMEResource()
+		if err != nil {
+			return "", err
+		}
+		return trimDNSName(r.CNAME), nil
+	case dnsmessage.TypeMX:
+		r, err := p.MXResource()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d %s", r.Pref, trimDNSName(r.MX)), nil
+	case dnsmessage.TypeTXT:
+		r, err := p.TXTResource()
+		if err != nil {
+			return "", err
+		}
+		return strings.Join(r.TXT, ""), nil
+	case dnsmessage.TypeNS:
+		r, err := p.NSResource()
+		if err != nil {
+			return "", err
+		}
+		return trimDNSName(r.NS), nil
+	case dnsmessage.TypeSOA:
+		r, err := p.SOAResource()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%s %s %d %d %d %d %d", trimDNSName(r.NS), trimDNSName(r.MBox), r.Serial, r.Refresh, r.Retry, r.Expire, r.MinTTL), nil
+	case dnsmessage.TypeSRV:
+		r, err := p.SRVResource()
+		if err != nil {
+			return "", err
+		}
+		return fmt.Sprintf("%d %d %d %s", r.Priority, r.Weight, r.Port, trimDNSName(r.Target)), nil
+	case typeCAA:
+		r, err := p.UnknownResource()
+		if err != nil {
+			return "", err
+		}
+		return formatCAA(r.Data)
+	default:
+		return "", fmt.Errorf("unsupported record type %v", t)
+	}
+}
+
+// formatCAA разбирает RDATA записи CAA (RFC 8659): flags, длина тега, тег, значение.
+func formatCAA(data []byte) (string, error) {
+	if len(data) < 2 || len(data) < 2+int(data[1]) {
+		return "", errors.New("malformed CAA record")
+	}
+	flags := data[0]
+	tagLen := int(data[1])
+	tag := string(data[2 : 2+tagLen])
+	value := string(data[2+tagLen:])
+	return fmt.Sprintf("%d %s %q", flags, tag, value), nil
+}
+
+func trimDNSName(n dnsmessage.Name) string {
+	return strings.TrimSuffix(n.String(), ".")
+}
+
+func normalizeDNSValue(v string) string {
+	return strings.ToLower(strings.TrimSuffix(strings.TrimSpace(v), "."))
+}
+
+func missingDNSValues(expected, answers []string) []string {
+	got := make(map[string]struct{}, len(answers))
+	for _, a := range answers {
+		got[normalizeDNSValue(a)] = struct{}{}
+	}
+
+	var missing []string
+	for _, e := range expected {
+		if _, ok := got[normalizeDNSValue(e)]; !ok {
+			missing = append(missing, e)
+		}
+	}
+	return missing
+}
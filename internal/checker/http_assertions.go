@@ -0,0 +1,197 @@
+package checker
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// defaultMaxAssertionBodyBytes ограничивает чтение тела, если max_body_bytes не задан.
+const defaultMaxAssertionBodyBytes = 1 << 20
+
+// BodyAssertions — проверки тела HTTP ответа.
+// Пустой JSONPathEquals означает проверку существования пути.
+type BodyAssertions struct {
+	Contains       string
+	NotContains    string
+	Regex          *regexp.Regexp
+	JSONPath       []jsonPathSegment
+	JSONPathRaw    string
+	JSONPathEquals string
+	MaxBytes       int64
+}
+
+func (a BodyAssertions) empty() bool {
+	return a.Contains == "" && a.NotContains == "" && a.Regex == nil && a.JSONPath == nil && a.MaxBytes <= 0
+}
+
+func bodyAssertionsFromParams(params models.CheckParams) (BodyAssertions, error) {
+	a := BodyAssertions{
+		Contains:       params.BodyContains,
+		NotContains:    params.BodyNotContains,
+		JSONPathRaw:    params.JSONPath,
+		JSONPathEquals: params.JSONPathEquals,
+		MaxBytes:       params.MaxBodyBytes,
+	}
+	if params.MaxBodyBytes < 0 {
+		return BodyAssertions{}, errors.New("max_body_bytes must be >= 0")
+	}
+	if params.BodyRegex != "" {
+		re, err := regexp.Compile(params.BodyRegex)
+		if err != nil {
+			return BodyAssertions{}, fmt.Errorf("invalid body_regex: %v", err)
+		}
+		a.Regex = re
+	}
+	if params.JSONPath != "" {
+		path, err := parseJSONPath(params.JSONPath)
+		if err != nil {
+			return BodyAssertions{}, fmt.Errorf("invalid json_path: %v", err)
+		}
+		a.JSONPath = path
+	} else if params.JSONPathEquals != "" {
+		return BodyAssertions{}, errors.New("json_path_equals requires json_path")
+	}
+	return a, nil
+}
+
+// checkBody читает тело (не больше лимита) и применяет проверки.
+// Возвращает outcome и сообщение об ошибке; пустой outcome — все проверки пройдены.
+func checkBody(body io.Reader, a BodyAssertions) (outcome, message string) {
+	limit := a.MaxBytes
+	if limit <= 0 {
+		limit = defaultMaxAssertionBodyBytes
+	}
+
+	data, err := io.ReadAll(io.LimitReader(body, limit+1))
+	if err != nil {
+		return "error", fmt.Sprintf("failed to read response body: %v", err)
+	}
+	if int64(len(data)) > limit {
+		if a.MaxBytes > 0 {
+			return "body_too_large", fmt.Sprintf("response body exceeds max_body_bytes (%d)", a.MaxBytes)
+		}
+		data = data[:limit]
+	}
+
+	text := string(data)
+	if a.Contains != "" && !strings.Contains(text, a.Contains) {
+		return "body_mismatch", fmt.Sprintf("response body does not contain %q", a.Contains)
+	}
+	if a.NotContains != "" && strings.Contains(text, a.NotContains) {
+		return "body_mismatch", fmt.Sprintf("response body contains forbidden %q", a.NotContains)
+	}
+	if a.Regex != nil && !a.Regex.Match(data) {
+		return "body_mismatch", fmt.Sprintf("response body does not match regex %q", a.Regex.String())
+	}
+	if a.JSONPath != nil {
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return "body_mismatch", fmt.Sprintf("response body is not valid JSON: %v", err)
+		}
+		value, ok := lookupJSONPath(doc, a.JSONPath)
+		if !ok {
+			return "body_mismatch", fmt.Sprintf("JSON path %s not found", a.JSONPathRaw)
+		}
+		if a.JSONPathEquals != "" {
+			if actual := jsonValueString(value); actual != a.JSONPathEquals {
+				return "body_mismatch", fmt.Sprintf("JSON path %s mismatch: expected '%s', got '%s'", a.JSONPathRaw, a.JSONPathEquals, actual)
+			}
+		}
+	}
+	return "", ""
+}
+
+// jsonPathSegment — ключ объекта или индекс массива (index >= 0).
+type jsonPathSegment struct {
+	key   string
+	index int
+}
+
+// parseJSONPath разбирает упрощённый JSONPath: $.a.b[0].c, a.b[0], $['a-b'].c
+func parseJSONPath(path string) ([]jsonPathSegment, error) {
+	p := strings.TrimSpace(path)
+	p = strings.TrimPrefix(p, "$")
+
+	var segments []jsonPathSegment
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			if end == 0 {
+				return nil, fmt.Errorf("empty key in %q", path)
+			}
+			segments = append(segments, jsonPathSegment{key: p[:end], index: -1})
+			p = p[end:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if end == -1 {
+				return nil, fmt.Errorf("unclosed bracket in %q", path)
+			}
+			inner := p[1:end]
+			p = p[end+1:]
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				segments = append(segments, jsonPathSegment{key: inner[1 : len(inner)-1], index: -1})
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil || idx < 0 {
+				return nil, fmt.Errorf("invalid index %q in %q", inner, path)
+			}
+			segments = append(segments, jsonPathSegment{index: idx})
+		default:
+			if len(segments) > 0 {
+				return nil, fmt.Errorf("unexpected %q in %q", p[0], path)
+			}
+			p = "." + p
+		}
+	}
+	if len(segments) == 0 {
+		return nil, fmt.Errorf("empty path %q", path)
+	}
+	return segments, nil
+}
+
+func lookupJSONPath(doc any, path []jsonPathSegment) (any, bool) {
+	current := doc
+	for _, seg := range path {
+		if seg.index >= 0 {
+			arr, ok := current.([]any)
+			if !ok || seg.index >= len(arr) {
+				return nil, false
+			}
+			current = arr[seg.index]
+			continue
+		}
+		obj, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		current, ok = obj[seg.key]
+		if !ok {
+			return nil, false
+		}
+	}
+	return current, true
+}
+
+func jsonValueString(v any) string {
+	if s, ok := v.(string); ok {
+		return s
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(raw)
+}
@@ -2,11 +2,16 @@ package checker
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"net/http/httptrace"
 	"strings"
 	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
 )
 
 type CheckResult struct {
@@ -16,6 +21,94 @@ type CheckResult struct {
 	Outcome      string
 	ErrorMessage string
 	Headers      map[string]string
+	Details      map[string]any
+	Timings      *models.PhaseTimings
+	Attempts     int
+}
+
+type httpChecker struct{}
+
+func (httpChecker) Type() string { return "http" }
+
+// HTTPOptions — параметры HTTP запроса и проверки ответа.
+// Нулевое значение соответствует поведению по умолчанию: успех для кодов ниже 400
+// и следование редиректам (не больше defaultMaxRedirects).
+type HTTPOptions struct {
+	Method           string
+	Body             string
+	ExpectedHeaders  map[string]string
+	BodyAssertions   BodyAssertions
+	ExpectedStatus   StatusSet
+	DisableRedirects bool
+	MaxRedirects     int
+}
+
+func (httpChecker) Validate(params *models.CheckParams) error {
+	if params.Path == "" {
+		params.Path = "/"
+	}
+	if params.MaxRedirects < 0 {
+		return errors.New("max_redirects must be >= 0")
+	}
+	_, err := httpOptionsFromParams(*params)
+	return err
+}
+
+func (httpChecker) Run(job CheckJob, timeout time.Duration) (CheckResult, error) {
+	opts, err := httpOptionsFromParams(job.Check.Params)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	fullURL := BuildHTTPURL(job.Domain.Name, job.Check.Params)
+	return RunHTTPCheckWithOptions(fullURL, opts, timeout), nil
+}
+
+func httpOptionsFromParams(params models.CheckParams) (HTTPOptions, error) {
+	assertions, err := bodyAssertionsFromParams(params)
+	if err != nil {
+		return HTTPOptions{}, err
+	}
+	expected, err := ParseStatusSet(params.ExpectedStatus)
+	if err != nil {
+		return HTTPOptions{}, err
+	}
+	return HTTPOptions{
+		Method:           NormalizeHTTPMethod(params.Method),
+		Body:             params.Body,
+		ExpectedHeaders:  params.Headers,
+		BodyAssertions:   assertions,
+		ExpectedStatus:   expected,
+		DisableRedirects: params.FollowRedirects != nil && !*params.FollowRedirects,
+		MaxRedirects:     params.MaxRedirects,
+	}, nil
+}
+
+func BuildHTTPURL(domainName string, params models.CheckParams) string {
+	path := params.Path
+	if path == "" {
+		path = "/"
+	}
+	scheme := params.Scheme
+	if scheme == "" {
+		scheme = "https"
+	}
+	host := domainName
+	if ip := net.ParseIP(domainName); ip != nil && ip.To4() == nil {
+		host = "[" + domainName + "]"
+	}
+	fullURL := scheme + "://" + host
+	if len(path) > 0 && path[0] != '/' {
+		fullURL += "/"
+	}
+	fullURL += path
+	return fullURL
+}
+
+func NormalizeHTTPMethod(method string) string {
+	if method == "" {
+		return "GET"
+	}
+	return method
 }
 
 func RunHTTPCheckWithMethod(url string, method string, body string, timeout time.Duration) CheckResult {
@@ -23,24 +116,104 @@ func RunHTTPCheckWithMethod(url string, method string, body string, timeout time
 }
 
 func RunHTTPCheckWithMethodAndHeaders(url string, method string, body string, expectedHeaders map[string]string, timeout time.Duration) CheckResult {
-	client := http.Client{Timeout: timeout}
+	return RunHTTPCheckWithOptions(url, HTTPOptions{Method: method, Body: body, ExpectedHeaders: expectedHeaders}, timeout)
+}
+
+func RunHTTPCheckWithOptions(url string, opts HTTPOptions, timeout time.Duration) CheckResult {
+	redirects := &redirectRecorder{disabled: opts.DisableRedirects, max: opts.MaxRedirects}
+	transport := newTracedTransport()
+	defer transport.CloseIdleConnections()
+	client := http.Client{Timeout: timeout, CheckRedirect: redirects.check, Transport: transport}
 	start := time.Now()
 
-	method = normalizeMethod(method)
-	req, err := createHTTPRequest(method, url, body)
+	method := normalizeMethod(opts.Method)
+	req, err := createHTTPRequest(method, url, opts.Body)
 	if err != nil {
 		return createErrorResult(err.Error())
 	}
+	tracer := &phaseTracer{}
+	req = req.WithContext(httptrace.WithClientTrace(req.Context(), tracer.clientTrace()))
 
 	resp, err := client.Do(req)
 	duration := time.Since(start).Milliseconds()
 
 	if err != nil {
-		return handleRequestError(err, int(duration))
+		result := handleRequestError(err, int(duration))
+		if errors.Is(err, errTooManyRedirects) {
+			result.Status = "error"
+			result.Outcome = "too_many_redirects"
+		}
+		redirects.attach(&result)
+		result.Timings = tracer.timings(time.Time{})
+		return result
 	}
 
 	defer closeResponseBody(resp.Body)
-	return createSuccessResultWithHeaders(resp, int(duration), expectedHeaders)
+	result := createSuccessResultWithHeaders(resp, int(duration), opts.ExpectedHeaders, opts.ExpectedStatus)
+	if result.Status == "success" && !opts.BodyAssertions.empty() {
+		applyBodyAssertions(&result, resp, opts.BodyAssertions)
+	}
+	drainResponseBody(resp.Body)
+	result.Timings = tracer.timings(time.Now())
+	redirects.attach(&result)
+	return result
+}
+
+var errTooManyRedirects = errors.New("too many redirects")
+
+// redirectRecorder реализует политику редиректов для http.Client и запоминает цепочку переходов.
+type redirectRecorder struct {
+	disabled bool
+	max      int
+	hops     []map[string]any
+}
+
+// check вызывается перед каждым переходом; непройденный переход тоже попадает в цепочку
+// с followed=false, чтобы было видно, куда вёл редирект.
+func (r *redirectRecorder) check(req *http.Request, via []*http.Request) error {
+	hop := map[string]any{
+		"from":     via[len(via)-1].URL.String(),
+		"to":       req.URL.String(),
+		"followed": true,
+	}
+	if req.Response != nil {
+		hop["status"] = req.Response.StatusCode
+	}
+	r.hops = append(r.hops, hop)
+
+	if r.disabled {
+		hop["followed"] = false
+		return http.ErrUseLastResponse
+	}
+	limit := r.max
+	if limit <= 0 {
+		limit = defaultMaxRedirects
+	}
+	if len(r.hops) > limit {
+		hop["followed"] = false
+		return fmt.Errorf("%w: stopped after %d", errTooManyRedirects, limit)
+	}
+	return nil
+}
+
+func (r *redirectRecorder) attach(result *CheckResult) {
+	if len(r.hops) == 0 {
+		return
+	}
+	if result.Details == nil {
+		result.Details = map[string]any{}
+	}
+	result.Details["redirects"] = r.hops
+}
+
+func applyBodyAssertions(result *CheckResult, resp *http.Response, assertions BodyAssertions) {
+	outcome, message := checkBody(resp.Body, assertions)
+	if outcome == "" {
+		return
+	}
+	result.Status = "error"
+	result.Outcome = outcome
+	result.ErrorMessage = message
 }
 
 func normalizeMethod(method string) string {
@@ -99,9 +272,12 @@ func closeResponseBody(body io.ReadCloser) {
 	}
 }
 
-func createSuccessResultWithHeaders(resp *http.Response, duration int, expectedHeaders map[string]string) CheckResult {
-	status, outcome := determineResponseStatus(resp.StatusCode)
+func createSuccessResultWithHeaders(resp *http.Response, duration int, expectedHeaders map[string]string, expectedStatus StatusSet) CheckResult {
+	status, outcome := determineResponseStatus(resp.StatusCode, expectedStatus)
 	errorMsg := ""
+	if outcome == "unexpected_status" {
+		errorMsg = fmt.Sprintf("unexpected status code %d (expected %s)", resp.StatusCode, expectedStatus)
+	}
 
 	if len(expectedHeaders) > 0 {
 		missingHeaders := []string{}
@@ -140,7 +316,13 @@ func createSuccessResultWithHeaders(resp *http.Response, duration int, expectedH
 	}
 }
 
-func determineResponseStatus(statusCode int) (status, outcome string) {
+func determineResponseStatus(statusCode int, expected StatusSet) (status, outcome string) {
+	if len(expected) > 0 {
+		if expected.Contains(statusCode) {
+			return "success", statusClass(statusCode)
+		}
+		return "failure", "unexpected_status"
+	}
 	switch {
 	case statusCode >= 500:
 		return "failure", "5xx"
@@ -0,0 +1,84 @@
+package checker
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// defaultMaxRedirects совпадает с ограничением стандартного http.Client.
+const defaultMaxRedirects = 10
+
+// statusRange — диапазон кодов ответа включительно.
+type statusRange struct {
+	from int
+	to   int
+}
+
+// StatusSet — набор ожидаемых кодов ответа, например "200,204" или "300-399".
+// Пустой набор означает поведение по умолчанию: успех для кодов ниже 400.
+type StatusSet []statusRange
+
+// ParseStatusSet разбирает список кодов и диапазонов через запятую.
+func ParseStatusSet(spec string) (StatusSet, error) {
+	var set StatusSet
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		fromStr, toStr, isRange := strings.Cut(part, "-")
+		from, err := parseStatusCode(fromStr)
+		if err != nil {
+			return nil, err
+		}
+		to := from
+		if isRange {
+			if to, err = parseStatusCode(toStr); err != nil {
+				return nil, err
+			}
+			if to < from {
+				return nil, fmt.Errorf("invalid status range %q", part)
+			}
+		}
+		set = append(set, statusRange{from: from, to: to})
+	}
+	if len(set) == 0 && strings.TrimSpace(spec) != "" {
+		return nil, fmt.Errorf("invalid expected_status %q", spec)
+	}
+	return set, nil
+}
+
+func parseStatusCode(s string) (int, error) {
+	code, err := strconv.Atoi(strings.TrimSpace(s))
+	if err != nil || code < 100 || code > 599 {
+		return 0, fmt.Errorf("invalid status code %q", strings.TrimSpace(s))
+	}
+	return code, nil
+}
+
+func (s StatusSet) Contains(code int) bool {
+	for _, r := range s {
+		if code >= r.from && code <= r.to {
+			return true
+		}
+	}
+	return false
+}
+
+func (s StatusSet) String() string {
+	parts := make([]string, 0, len(s))
+	for _, r := range s {
+		if r.from == r.to {
+			parts = append(parts, strconv.Itoa(r.from))
+		} else {
+			parts = append(parts, fmt.Sprintf("%d-%d", r.from, r.to))
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// statusClass возвращает класс кода ответа: "2xx", "3xx" и т.д.
+func statusClass(code int) string {
+	return fmt.Sprintf("%dxx", code/100)
+}
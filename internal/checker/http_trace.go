@@ -0,0 +1,110 @@
+package checker
+
+import (
+	"crypto/tls"
+	"io"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// maxTransferDrainBytes ограничивает объём тела, который вычитывается для замера фазы передачи.
+const maxTransferDrainBytes = 10 << 20
+
+// phaseTracer собирает длительности фаз запроса через httptrace.
+// При редиректах DNS, connect и TLS суммируются по всем переходам,
+// а TTFB относится к последнему запросу.
+type phaseTracer struct {
+	mu sync.Mutex
+
+	dnsStart, connectStart, tlsStart time.Time
+	wroteRequest, firstByte          time.Time
+
+	dns, connect, tlsHandshake time.Duration
+}
+
+func (t *phaseTracer) clientTrace() *httptrace.ClientTrace {
+	return &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.mu.Lock()
+			t.dnsStart = time.Now()
+			t.mu.Unlock()
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.mu.Lock()
+			t.dns += sinceIfSet(t.dnsStart)
+			t.mu.Unlock()
+		},
+		ConnectStart: func(string, string) {
+			t.mu.Lock()
+			t.connectStart = time.Now()
+			t.mu.Unlock()
+		},
+		ConnectDone: func(string, string, error) {
+			t.mu.Lock()
+			t.connect += sinceIfSet(t.connectStart)
+			t.mu.Unlock()
+		},
+		TLSHandshakeStart: func() {
+			t.mu.Lock()
+			t.tlsStart = time.Now()
+			t.mu.Unlock()
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.mu.Lock()
+			t.tlsHandshake += sinceIfSet(t.tlsStart)
+			t.mu.Unlock()
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.mu.Lock()
+			t.wroteRequest = time.Now()
+			t.mu.Unlock()
+		},
+		GotFirstResponseByte: func() {
+			t.mu.Lock()
+			t.firstByte = time.Now()
+			t.mu.Unlock()
+		},
+	}
+}
+
+func sinceIfSet(start time.Time) time.Duration {
+	if start.IsZero() {
+		return 0
+	}
+	return time.Since(start)
+}
+
+// timings формирует итоговые длительности; transferDone — момент окончания чтения тела.
+func (t *phaseTracer) timings(transferDone time.Time) *models.PhaseTimings {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	timings := &models.PhaseTimings{
+		DNSMS:     int(t.dns.Milliseconds()),
+		ConnectMS: int(t.connect.Milliseconds()),
+		TLSMS:     int(t.tlsHandshake.Milliseconds()),
+	}
+	if !t.wroteRequest.IsZero() && !t.firstByte.IsZero() {
+		timings.TTFBMS = int(t.firstByte.Sub(t.wroteRequest).Milliseconds())
+	}
+	if !t.firstByte.IsZero() && !transferDone.IsZero() {
+		timings.TransferMS = int(transferDone.Sub(t.firstByte).Milliseconds())
+	}
+	return timings
+}
+
+// newTracedTransport возвращает транспорт без keep-alive, чтобы каждая проверка
+// проходила DNS, connect и TLS заново и фазы были сопоставимы между запусками.
+func newTracedTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DisableKeepAlives = true
+	return transport
+}
+
+func drainResponseBody(body io.Reader) {
+	_, _ = io.Copy(io.Discard, io.LimitReader(body, maxTransferDrainBytes))
+}
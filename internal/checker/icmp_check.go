@@ -2,27 +2,98 @@ package checker
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"math"
 	"runtime"
 	"time"
 
+	"github.com/MimoJanra/DomainPulse/internal/models"
 	probing "github.com/prometheus-community/pro-bing"
 )
 
+const (
+	maxICMPPacketCount     = 100
+	minICMPPacketInterval  = 10 * time.Millisecond
+	defaultICMPPacketCount = 1
+)
+
+// ICMPOptions — параметры серии ping-запросов.
+// MaxLossPercent == nil означает, что для успеха достаточно одного ответа.
+type ICMPOptions struct {
+	Count          int
+	Interval       time.Duration
+	MaxLossPercent *float64
+}
+
+type icmpChecker struct{}
+
+func (icmpChecker) Type() string { return "icmp" }
+
+func (icmpChecker) Validate(params *models.CheckParams) error {
+	if params.PacketCount < 0 || params.PacketCount > maxICMPPacketCount {
+		return fmt.Errorf("packet_count must be between 0 and %d (0 = default)", maxICMPPacketCount)
+	}
+	if params.PacketIntervalMS < 0 {
+		return errors.New("packet_interval_ms must be >= 0")
+	}
+	if params.PacketIntervalMS > 0 && time.Duration(params.PacketIntervalMS)*time.Millisecond < minICMPPacketInterval {
+		return fmt.Errorf("packet_interval_ms must be at least %d", minICMPPacketInterval.Milliseconds())
+	}
+	if p := params.MaxPacketLossPercent; p != nil && (*p < 0 || *p > 100) {
+		return errors.New("max_packet_loss_percent must be between 0 and 100")
+	}
+
+	opts := icmpOptionsFromParams(*params)
+	timeout := DefaultCheckTimeout()
+	if params.TimeoutMS > 0 {
+		timeout = time.Duration(params.TimeoutMS) * time.Millisecond
+	}
+	if time.Duration(opts.Count-1)*opts.Interval >= timeout {
+		return errors.New("packet_count * packet_interval_ms exceeds check timeout")
+	}
+	return nil
+}
+
+func (icmpChecker) Run(job CheckJob, timeout time.Duration) (CheckResult, error) {
+	return RunICMPCheckWithOptions(job.Domain.Name, icmpOptionsFromParams(job.Check.Params), timeout), nil
+}
+
+func icmpOptionsFromParams(params models.CheckParams) ICMPOptions {
+	opts := ICMPOptions{
+		Count:          params.PacketCount,
+		Interval:       time.Duration(params.PacketIntervalMS) * time.Millisecond,
+		MaxLossPercent: params.MaxPacketLossPercent,
+	}
+	if opts.Count <= 0 {
+		opts.Count = defaultICMPPacketCount
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = time.Second
+	}
+	return opts
+}
+
 func RunICMPCheck(host string, timeout time.Duration) CheckResult {
+	return RunICMPCheckWithOptions(host, ICMPOptions{Count: defaultICMPPacketCount, Interval: time.Second}, timeout)
+}
+
+func RunICMPCheckWithOptions(host string, opts ICMPOptions, timeout time.Duration) CheckResult {
 	start := time.Now()
 
-	pinger, err := createPinger(host, timeout)
+	pinger, err := createPinger(host, opts, timeout)
 	if err != nil {
 		return createICMPErrorResult(fmt.Sprintf("failed to create pinger: %v", err), start)
 	}
 
-	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	// pinger сам останавливается по pinger.Timeout и отдаёт частичную статистику;
+	// контекст с запасом страхует от зависания Run.
+	ctx, cancel := context.WithTimeout(context.Background(), timeout+time.Second)
 	defer cancel()
 
 	done := make(chan CheckResult, 1)
 
-	go runPingerAsync(pinger, start, done)
+	go runPingerAsync(pinger, opts, start, done)
 
 	select {
 	case result := <-done:
@@ -33,13 +104,14 @@ func RunICMPCheck(host string, timeout time.Duration) CheckResult {
 	}
 }
 
-func createPinger(host string, timeout time.Duration) (*probing.Pinger, error) {
+func createPinger(host string, opts ICMPOptions, timeout time.Duration) (*probing.Pinger, error) {
 	pinger, err := probing.NewPinger(host)
 	if err != nil {
 		return nil, err
 	}
 
-	pinger.Count = 1
+	pinger.Count = opts.Count
+	pinger.Interval = opts.Interval
 	pinger.Timeout = timeout
 	setPingerPrivilege(pinger)
 
@@ -54,32 +126,85 @@ func setPingerPrivilege(pinger *probing.Pinger) {
 	}
 }
 
-func runPingerAsync(pinger *probing.Pinger, start time.Time, done chan CheckResult) {
+func runPingerAsync(pinger *probing.Pinger, opts ICMPOptions, start time.Time, done chan CheckResult) {
 	err := pinger.Run()
 	if err != nil {
 		done <- createICMPErrorResult(fmt.Sprintf("ping failed: %v", err), start)
 		return
 	}
 
-	result := processPingStatistics(pinger, start)
+	result := processPingStatistics(pinger.Statistics(), opts, start)
 	done <- result
 }
 
-func processPingStatistics(pinger *probing.Pinger, start time.Time) CheckResult {
-	stats := pinger.Statistics()
+func processPingStatistics(stats *probing.Statistics, opts ICMPOptions, start time.Time) CheckResult {
+	details := pingDetails(stats)
 	if stats.PacketsRecv == 0 {
-		return createICPTimeoutResult(start, "no response received")
+		result := createICPTimeoutResult(start, "no response received")
+		result.Details = details
+		return result
 	}
 
 	rtt := calculateRTT(stats)
+	if opts.MaxLossPercent != nil && stats.PacketLoss > *opts.MaxLossPercent {
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   int(rtt),
+			Outcome:      "packet_loss",
+			ErrorMessage: fmt.Sprintf("packet loss %.1f%% exceeds threshold %.1f%%", stats.PacketLoss, *opts.MaxLossPercent),
+			Details:      details,
+		}
+	}
+
 	return CheckResult{
 		Status:       "success",
 		DurationMS:   int(rtt),
 		Outcome:      "success",
 		ErrorMessage: "",
+		Details:      details,
 	}
 }
 
+// pingDetails сохраняет потери и RTT в миллисекундах с дробной частью:
+// в локальных сетях RTT часто меньше миллисекунды.
+// jitter_ms — среднее абсолютное изменение RTT между соседними ответами.
+func pingDetails(stats *probing.Statistics) map[string]any {
+	return map[string]any{
+		"packets_sent":        stats.PacketsSent,
+		"packets_recv":        stats.PacketsRecv,
+		"packet_loss_percent": roundTo(stats.PacketLoss, 2),
+		"min_rtt_ms":          durationMS(stats.MinRtt),
+		"avg_rtt_ms":          durationMS(stats.AvgRtt),
+		"max_rtt_ms":          durationMS(stats.MaxRtt),
+		"stddev_rtt_ms":       durationMS(stats.StdDevRtt),
+		"jitter_ms":           durationMS(rttJitter(stats.Rtts)),
+	}
+}
+
+func rttJitter(rtts []time.Duration) time.Duration {
+	if len(rtts) < 2 {
+		return 0
+	}
+	var sum time.Duration
+	for i := 1; i < len(rtts); i++ {
+		diff := rtts[i] - rtts[i-1]
+		if diff < 0 {
+			diff = -diff
+		}
+		sum += diff
+	}
+	return sum / time.Duration(len(rtts)-1)
+}
+
+func durationMS(d time.Duration) float64 {
+	return roundTo(float64(d)/float64(time.Millisecond), 3)
+}
+
+func roundTo(v float64, digits int) float64 {
+	scale := math.Pow(10, float64(digits))
+	return math.Round(v*scale) / scale
+}
+
 func calculateRTT(stats *probing.Statistics) int64 {
 	rtt := stats.AvgRtt.Milliseconds()
 	if rtt == 0 && stats.MinRtt > 0 {
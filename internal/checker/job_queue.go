@@ -0,0 +1,185 @@
+package checker
+
+import (
+	"container/heap"
+	"sync"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// Приоритеты задач в очереди: сначала недоступные проверки, затем просроченные, затем остальные.
+const (
+	priorityNormal = iota
+	priorityOverdue
+	priorityFailing
+)
+
+// jobQueueCapacity — ёмкость очереди по умолчанию.
+const jobQueueCapacity = 100
+
+type queuedJob struct {
+	job      CheckJob
+	priority int
+	seq      uint64
+	index    int
+}
+
+// jobHeap упорядочивает задачи по убыванию приоритета, а при равном приоритете — по времени постановки.
+type jobHeap []*queuedJob
+
+func (h jobHeap) Len() int { return len(h) }
+
+func (h jobHeap) Less(i, j int) bool {
+	if h[i].priority != h[j].priority {
+		return h[i].priority > h[j].priority
+	}
+	return h[i].seq < h[j].seq
+}
+
+func (h jobHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *jobHeap) Push(x any) {
+	item := x.(*queuedJob)
+	item.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *jobHeap) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*h = old[:n-1]
+	return item
+}
+
+// jobQueue — ограниченная очередь задач с приоритетами. Задача для проверки, которая уже ждёт
+// в очереди, объединяется с ожидающей вместо добавления дубликата.
+type jobQueue struct {
+	mu       sync.Mutex
+	cond     *sync.Cond
+	items    jobHeap
+	pending  map[int]*queuedJob
+	capacity int
+	seq      uint64
+	closed   bool
+
+	enqueued       uint64
+	coalesced      uint64
+	dropped        uint64
+	droppedByCheck map[int]uint64
+}
+
+func newJobQueue(capacity int) *jobQueue {
+	q := &jobQueue{
+		pending:        make(map[int]*queuedJob),
+		capacity:       capacity,
+		droppedByCheck: make(map[int]uint64),
+	}
+	q.cond = sync.NewCond(&q.mu)
+	return q
+}
+
+// push ставит задачу в очередь. Если очередь заполнена, вытесняется задача с наименьшим приоритетом
+// (из равных — поставленная последней), а если приоритет новой задачи не выше — отбрасывается она сама.
+// Возвращает отброшенную задачу и shed=true, если что-то было отброшено.
+func (q *jobQueue) push(job CheckJob, priority int) (dropped CheckJob, shed bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if q.closed {
+		return CheckJob{}, false
+	}
+
+	if item, ok := q.pending[job.Check.ID]; ok {
+		item.job = job
+		if priority > item.priority {
+			item.priority = priority
+			heap.Fix(&q.items, item.index)
+		}
+		q.coalesced++
+		return CheckJob{}, false
+	}
+
+	if len(q.items) >= q.capacity {
+		worst := q.lowest()
+		if worst == nil || worst.priority >= priority {
+			q.recordDrop(job.Check.ID)
+			return job, true
+		}
+		heap.Remove(&q.items, worst.index)
+		delete(q.pending, worst.job.Check.ID)
+		q.recordDrop(worst.job.Check.ID)
+		dropped, shed = worst.job, true
+	}
+
+	q.seq++
+	item := &queuedJob{job: job, priority: priority, seq: q.seq}
+	heap.Push(&q.items, item)
+	q.pending[job.Check.ID] = item
+	q.enqueued++
+	q.cond.Signal()
+	return dropped, shed
+}
+
+func (q *jobQueue) lowest() *queuedJob {
+	var worst *queuedJob
+	for _, item := range q.items {
+		if worst == nil || item.priority < worst.priority || (item.priority == worst.priority && item.seq > worst.seq) {
+			worst = item
+		}
+	}
+	return worst
+}
+
+func (q *jobQueue) recordDrop(checkID int) {
+	q.dropped++
+	q.droppedByCheck[checkID]++
+}
+
+// pop ждёт задачу с наибольшим приоритетом; ok=false после закрытия очереди.
+func (q *jobQueue) pop() (CheckJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	for len(q.items) == 0 && !q.closed {
+		q.cond.Wait()
+	}
+	if q.closed {
+		return CheckJob{}, false
+	}
+	item := heap.Pop(&q.items).(*queuedJob)
+	delete(q.pending, item.job.Check.ID)
+	return item.job, true
+}
+
+func (q *jobQueue) close() {
+	q.mu.Lock()
+	q.closed = true
+	q.mu.Unlock()
+	q.cond.Broadcast()
+}
+
+func (q *jobQueue) stats() models.QueueStats {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	stats := models.QueueStats{
+		Depth:     len(q.items),
+		Capacity:  q.capacity,
+		Enqueued:  q.enqueued,
+		Coalesced: q.coalesced,
+		Dropped:   q.dropped,
+	}
+	if len(q.droppedByCheck) > 0 {
+		stats.DroppedByCheck = make(map[int]uint64, len(q.droppedByCheck))
+		for id, n := range q.droppedByCheck {
+			stats.DroppedByCheck[id] = n
+		}
+	}
+	return stats
+}
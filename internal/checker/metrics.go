@@ -0,0 +1,131 @@
+package checker
+
+import (
+	"log"
+	"sort"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+	"github.com/MimoJanra/DomainPulse/internal/notifications"
+	"github.com/MimoJanra/DomainPulse/internal/storage"
+)
+
+// ResultKey — статус и исход результата, по которым считаются результаты проверки.
+type ResultKey struct {
+	Status  string
+	Outcome string
+}
+
+// CheckMetricsSnapshot — метрики проверки на момент снимка. Up, LastDurationMS и LastResultTime
+// заполнены, только если HasState: результаты во время окон обслуживания и пропущенные запуски их не меняют.
+type CheckMetricsSnapshot struct {
+	CheckID        int
+	Domain         string
+	Type           string
+	HasState       bool
+	Up             bool
+	LastDurationMS int
+	LastResultTime time.Time
+	ErrorStreak    int
+	Results        map[ResultKey]uint64
+}
+
+// PoolMetrics — снимок метрик worker pool: очередь, проверки и отправка уведомлений по типу канала.
+type PoolMetrics struct {
+	Queue                models.QueueStats
+	Checks               []CheckMetricsSnapshot
+	NotificationsSent    map[string]uint64
+	NotificationFailures map[string]uint64
+}
+
+// recordResult учитывает сохранённый результат проверки в счётчиках по статусу и исходу.
+func (wp *WorkerPool) recordResult(job CheckJob, status, outcome string, durationMS int) {
+	metrics := wp.getOrCreateMetrics(job.Check.ID)
+
+	metrics.mu.Lock()
+	defer metrics.mu.Unlock()
+
+	metrics.domain = job.Domain.Name
+	metrics.checkType = job.Check.Type
+	if metrics.results == nil {
+		metrics.results = make(map[ResultKey]uint64)
+	}
+	metrics.results[ResultKey{Status: status, Outcome: outcome}]++
+
+	if status == storage.StatusMaintenance || status == storage.StatusSkipped {
+		return
+	}
+	metrics.hasState = true
+	metrics.up = !isDownStatus(status)
+	metrics.lastDurationMS = durationMS
+	metrics.lastResultTime = time.Now()
+}
+
+// sendNotification отправляет уведомление и учитывает результат отправки.
+func (wp *WorkerPool) sendNotification(settings models.NotificationSettings, msg notifications.NotificationMessage) {
+	err := wp.notifSender.SendNotification(settings, msg)
+
+	wp.notifMu.Lock()
+	if err != nil {
+		wp.notifFailures[settings.Type]++
+	} else {
+		wp.notifSent[settings.Type]++
+	}
+	wp.notifMu.Unlock()
+
+	if err != nil {
+		log.Printf("failed to send %s notification %d for check %d: %v", settings.Type, settings.ID, msg.CheckID, err)
+	}
+}
+
+// forgetCheck удаляет метрики проверки, которая больше не выполняется.
+func (wp *WorkerPool) forgetCheck(checkID int) {
+	wp.metricsMu.Lock()
+	delete(wp.checkMetrics, checkID)
+	wp.metricsMu.Unlock()
+}
+
+// Metrics возвращает снимок метрик. Проверки без результатов с момента запуска не включаются.
+func (wp *WorkerPool) Metrics() PoolMetrics {
+	m := PoolMetrics{
+		Queue:                wp.QueueStats(),
+		NotificationsSent:    make(map[string]uint64),
+		NotificationFailures: make(map[string]uint64),
+	}
+
+	wp.metricsMu.RLock()
+	for id, metrics := range wp.checkMetrics {
+		metrics.mu.Lock()
+		if metrics.results != nil {
+			snapshot := CheckMetricsSnapshot{
+				CheckID:        id,
+				Domain:         metrics.domain,
+				Type:           metrics.checkType,
+				HasState:       metrics.hasState,
+				Up:             metrics.up,
+				LastDurationMS: metrics.lastDurationMS,
+				LastResultTime: metrics.lastResultTime,
+				ErrorStreak:    metrics.errorCount,
+				Results:        make(map[ResultKey]uint64, len(metrics.results)),
+			}
+			for key, n := range metrics.results {
+				snapshot.Results[key] = n
+			}
+			m.Checks = append(m.Checks, snapshot)
+		}
+		metrics.mu.Unlock()
+	}
+	wp.metricsMu.RUnlock()
+	sort.Slice(m.Checks, func(i, j int) bool { return m.Checks[i].CheckID < m.Checks[j].CheckID })
+
+	wp.notifMu.Lock()
+	for channel, n := range wp.notifSent {
+		m.NotificationsSent[channel] = n
+	}
+	for channel, n := range wp.notifFailures {
+		m.NotificationFailures[channel] = n
+	}
+	wp.notifMu.Unlock()
+
+	return m
+}
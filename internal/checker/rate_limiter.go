@@ -125,7 +125,7 @@ func (rl *RateLimiter) calculateTokensToAdd(elapsed time.Duration) int {
 }
 
 func (rl *RateLimiter) waitForAvailableTokens(now *time.Time) {
-	for rl.tokens <= 0 {
+	for rl.tokens <= 0 && rl.maxTokens > 0 {
 		rl.waitUntilNextRefill(now)
 		rl.refillTokens(*now)
 	}
@@ -151,6 +151,18 @@ func (rl *RateLimiter) consumeToken(now time.Time) {
 	rl.lastRequest = now
 }
 
+// SetLimit меняет лимит запросов в минуту; 0 снимает ограничение. Ожидающие Wait продолжают ждать по новому лимиту.
+func (rl *RateLimiter) SetLimit(maxTokensPerMinute int) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.maxTokens = maxTokensPerMinute
+	rl.refillRate = maxTokensPerMinute
+	if rl.tokens > maxTokensPerMinute {
+		rl.tokens = maxTokensPerMinute
+	}
+}
+
 var GlobalRateLimiter *RateLimiter
 
 func InitGlobalRateLimiter(maxRequestsPerMinute int) {
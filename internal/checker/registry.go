@@ -0,0 +1,198 @@
+package checker
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// Checker — реализация одного типа проверки (models.Check.Type).
+// Validate нормализует параметры (подставляет значения по умолчанию) и проверяет их,
+// Run выполняет проверку и формирует результат.
+type Checker interface {
+	Type() string
+	Validate(params *models.CheckParams) error
+	Run(job CheckJob, timeout time.Duration) (CheckResult, error)
+}
+
+// PersistentChecker — проверка, которая держит постоянное соединение и сама сообщает о событиях
+// вместо периодического запуска планировщиком.
+type PersistentChecker interface {
+	Checker
+	RunPersistent(job CheckJob, timeout time.Duration, onEvent func(CheckResult), stopChan chan struct{})
+}
+
+// defaultCheckTimeout — таймаут проверок без timeout_ms в наносекундах, задаётся конфигурацией.
+var defaultCheckTimeout atomic.Int64
+
+const fallbackCheckTimeout = 10 * time.Second
+
+// SetDefaultCheckTimeout задаёт таймаут проверок без timeout_ms.
+func SetDefaultCheckTimeout(timeout time.Duration) {
+	defaultCheckTimeout.Store(int64(timeout))
+}
+
+// DefaultCheckTimeout возвращает таймаут проверок без timeout_ms.
+func DefaultCheckTimeout() time.Duration {
+	if timeout := time.Duration(defaultCheckTimeout.Load()); timeout > 0 {
+		return timeout
+	}
+	return fallbackCheckTimeout
+}
+
+const (
+	maxRetries        = 10
+	defaultRetryDelay = time.Second
+	maxRetryDelay     = time.Minute
+)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Checker{}
+)
+
+func init() {
+	for _, c := range []Checker{
+		httpChecker{},
+		icmpChecker{},
+		tcpChecker{},
+		udpChecker{},
+		tlsChecker{},
+		dnsChecker{},
+	} {
+		if err := Register(c); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Register добавляет тип проверки в реестр. Повторная регистрация типа — ошибка.
+func Register(c Checker) error {
+	checkType := strings.ToLower(c.Type())
+	if checkType == "" {
+		return fmt.Errorf("checker type is empty")
+	}
+
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, exists := registry[checkType]; exists {
+		return fmt.Errorf("checker %q already registered", checkType)
+	}
+	registry[checkType] = c
+	return nil
+}
+
+func Lookup(checkType string) (Checker, bool) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	c, ok := registry[strings.ToLower(checkType)]
+	return c, ok
+}
+
+func RegisteredTypes() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// ValidateCheck проверяет, что тип зарегистрирован, и валидирует параметры через соответствующий Checker.
+func ValidateCheck(checkType string, params *models.CheckParams) error {
+	c, ok := Lookup(checkType)
+	if !ok {
+		return fmt.Errorf("unsupported check type")
+	}
+	if err := validateRetries(*params); err != nil {
+		return err
+	}
+	if err := validateSchedule(*params); err != nil {
+		return err
+	}
+	if err := validateSeverity(*params); err != nil {
+		return err
+	}
+	return c.Validate(params)
+}
+
+func validateRetries(params models.CheckParams) error {
+	if params.Retries < 0 || params.Retries > maxRetries {
+		return fmt.Errorf("retries must be between 0 and %d", maxRetries)
+	}
+	if params.RetryDelayMS < 0 || time.Duration(params.RetryDelayMS)*time.Millisecond > maxRetryDelay {
+		return fmt.Errorf("retry_delay_ms must be between 0 and %d", maxRetryDelay.Milliseconds())
+	}
+	return nil
+}
+
+func validateSeverity(params models.CheckParams) error {
+	switch params.Severity {
+	case "", models.SeverityCritical, models.SeverityError, models.SeverityWarning, models.SeverityInfo:
+		return nil
+	}
+	return fmt.Errorf("severity must be 'critical', 'error', 'warning' or 'info'")
+}
+
+func retryDelay(params models.CheckParams) time.Duration {
+	if params.RetryDelayMS > 0 {
+		return time.Duration(params.RetryDelayMS) * time.Millisecond
+	}
+	return defaultRetryDelay
+}
+
+func CheckTimeout(check models.Check) time.Duration {
+	if check.Params.TimeoutMS > 0 {
+		return time.Duration(check.Params.TimeoutMS) * time.Millisecond
+	}
+	return DefaultCheckTimeout()
+}
+
+// RunCheck выполняет проверку через зарегистрированный для её типа Checker.
+func RunCheck(job CheckJob) (CheckResult, error) {
+	c, ok := Lookup(job.Check.Type)
+	if !ok {
+		return CheckResult{}, fmt.Errorf("unsupported check type: %s", job.Check.Type)
+	}
+	result, err := c.Run(job, CheckTimeout(job.Check))
+	if err != nil {
+		return CheckResult{}, err
+	}
+	result.Attempts = 1
+	return result, nil
+}
+
+// RunCheckWithRetries повторяет неуспешную проверку до params.retries раз с паузой retry_delay_ms
+// и возвращает результат последней попытки с числом выполненных попыток в Attempts.
+// Закрытие stop прерывает ожидание и возвращает последний полученный результат.
+func RunCheckWithRetries(job CheckJob, stop <-chan struct{}) (CheckResult, error) {
+	retries := job.Check.Params.Retries
+	delay := retryDelay(job.Check.Params)
+
+	for attempt := 1; ; attempt++ {
+		result, err := RunCheck(job)
+		if err != nil {
+			return CheckResult{}, err
+		}
+		result.Attempts = attempt
+		if !isDownStatus(result.Status) || attempt > retries {
+			return result, nil
+		}
+
+		select {
+		case <-stop:
+			return result, nil
+		case <-time.After(delay):
+		}
+	}
+}
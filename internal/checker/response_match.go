@@ -0,0 +1,190 @@
+package checker
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+const (
+	defaultMaxResponseBytes = 4096
+	maxResponsePreview      = 256
+)
+
+// ResponseExpectation — ожидание ответа для tcp/udp проверок.
+// Mode: "string" (подстрока), "regex" или "hex" (последовательность байт в hex).
+// Пустое ожидание означает, что ответ только читается и сохраняется.
+type ResponseExpectation struct {
+	Mode        string
+	Text        string
+	Regex       *regexp.Regexp
+	Bytes       []byte
+	MaxBytes    int
+	ReadTimeout time.Duration
+}
+
+// responseExpectationFromParams возвращает ok=false, если чтение ответа не запрошено.
+func responseExpectationFromParams(params models.CheckParams) (ResponseExpectation, bool, error) {
+	if params.ReadTimeoutMS < 0 {
+		return ResponseExpectation{}, false, errors.New("read_timeout_ms must be >= 0")
+	}
+	if params.MaxResponseBytes < 0 {
+		return ResponseExpectation{}, false, errors.New("max_response_bytes must be >= 0")
+	}
+
+	e := ResponseExpectation{
+		Mode:        strings.ToLower(params.ExpectMode),
+		Text:        params.Expect,
+		MaxBytes:    params.MaxResponseBytes,
+		ReadTimeout: time.Duration(params.ReadTimeoutMS) * time.Millisecond,
+	}
+	if e.Mode == "" {
+		e.Mode = "string"
+	}
+	if e.MaxBytes == 0 {
+		e.MaxBytes = defaultMaxResponseBytes
+	}
+
+	switch e.Mode {
+	case "string":
+	case "regex":
+		re, err := regexp.Compile(params.Expect)
+		if err != nil {
+			return ResponseExpectation{}, false, fmt.Errorf("invalid expect regex: %v", err)
+		}
+		e.Regex = re
+	case "hex":
+		raw, err := decodeHex(params.Expect)
+		if err != nil {
+			return ResponseExpectation{}, false, fmt.Errorf("invalid expect hex: %v", err)
+		}
+		e.Bytes = raw
+	default:
+		return ResponseExpectation{}, false, fmt.Errorf("unsupported expect_mode %q (string, regex, hex)", params.ExpectMode)
+	}
+
+	enabled := params.Expect != "" || params.ReadTimeoutMS > 0 || params.MaxResponseBytes > 0
+	return e, enabled, nil
+}
+
+// decodePayload декодирует payload согласно payload_encoding: text (по умолчанию), hex или base64.
+func decodePayload(payload, encoding string) ([]byte, error) {
+	switch strings.ToLower(encoding) {
+	case "", "text":
+		return []byte(payload), nil
+	case "hex":
+		raw, err := decodeHex(payload)
+		if err != nil {
+			return nil, fmt.Errorf("invalid hex payload: %v", err)
+		}
+		return raw, nil
+	case "base64":
+		raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(payload))
+		if err != nil {
+			return nil, fmt.Errorf("invalid base64 payload: %v", err)
+		}
+		return raw, nil
+	default:
+		return nil, fmt.Errorf("unsupported payload_encoding %q (text, hex, base64)", encoding)
+	}
+}
+
+// decodeHex допускает пробелы и двоеточия между байтами: "de ad be ef", "de:ad:be:ef".
+func decodeHex(s string) ([]byte, error) {
+	cleaned := strings.NewReplacer(" ", "", ":", "", "\n", "", "\t", "").Replace(s)
+	cleaned = strings.TrimPrefix(strings.TrimPrefix(cleaned, "0x"), "0X")
+	return hex.DecodeString(cleaned)
+}
+
+func (e ResponseExpectation) hasPattern() bool {
+	return e.Text != ""
+}
+
+func (e ResponseExpectation) Match(data []byte) bool {
+	switch {
+	case !e.hasPattern():
+		return true
+	case e.Regex != nil:
+		return e.Regex.Match(data)
+	case e.Bytes != nil:
+		return bytes.Contains(data, e.Bytes)
+	default:
+		return bytes.Contains(data, []byte(e.Text))
+	}
+}
+
+func (e ResponseExpectation) String() string {
+	return fmt.Sprintf("%s %q", e.Mode, e.Text)
+}
+
+// readStreamResponse читает ответ из потокового соединения, пока ожидание не совпадёт,
+// не будет прочитано MaxBytes, не истечёт дедлайн или сервер не закроет соединение.
+// Ошибка возвращается только если не удалось прочитать ни одного байта.
+func readStreamResponse(conn net.Conn, e ResponseExpectation, deadline time.Time) ([]byte, error) {
+	if err := conn.SetReadDeadline(deadline); err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, 0, min(e.MaxBytes, 1024))
+	buf := make([]byte, 1024)
+	for len(data) < e.MaxBytes {
+		n, err := conn.Read(buf[:min(len(buf), e.MaxBytes-len(data))])
+		data = append(data, buf[:n]...)
+		if e.hasPattern() && e.Match(data) {
+			return data, nil
+		}
+		if err != nil {
+			if len(data) > 0 && (errors.Is(err, io.EOF) || isNetworkTimeout(err)) {
+				return data, nil
+			}
+			return data, err
+		}
+		if !e.hasPattern() && n > 0 && n < len(buf) {
+			// Без шаблона достаточно первой порции данных (баннер).
+			return data, nil
+		}
+	}
+	return data, nil
+}
+
+// responseDeadline выбирает дедлайн чтения: read_timeout_ms, но не позже общего таймаута проверки.
+func responseDeadline(e ResponseExpectation, start time.Time, timeout time.Duration) time.Time {
+	deadline := start.Add(timeout)
+	if e.ReadTimeout > 0 {
+		if readDeadline := time.Now().Add(e.ReadTimeout); readDeadline.Before(deadline) {
+			deadline = readDeadline
+		}
+	}
+	return deadline
+}
+
+// responsePreview возвращает начало ответа для details: текст, если он печатаемый, иначе hex.
+func responsePreview(data []byte) string {
+	preview := data
+	if len(preview) > maxResponsePreview {
+		preview = preview[:maxResponsePreview]
+	}
+	if utf8.Valid(preview) && !bytes.ContainsFunc(preview, func(r rune) bool {
+		return r < 0x20 && r != '\r' && r != '\n' && r != '\t'
+	}) {
+		return string(preview)
+	}
+	return hex.EncodeToString(preview)
+}
+
+func responseDetails(data []byte) map[string]any {
+	return map[string]any{
+		"response":       responsePreview(data),
+		"response_bytes": len(data),
+	}
+}
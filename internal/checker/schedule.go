@@ -0,0 +1,124 @@
+package checker
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strconv"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/cron"
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// startupSpread — максимальная задержка запуска просроченных проверок после старта планировщика,
+// чтобы они не запускались одновременно.
+const startupSpread = time.Minute
+
+// checkSchedule вычисляет время запуска проверки: first — первый запуск после (пере)планирования
+// с учётом последнего выполнения, next — следующий запуск после запуска в момент prev.
+type checkSchedule interface {
+	first(checkID int, lastRun, now time.Time) time.Time
+	next(prev time.Time) time.Time
+}
+
+// intervalSchedule запускает проверку каждые interval со сдвигом phase относительно начала эпохи.
+// Сдвиг определяется ID проверки, поэтому проверки с одинаковым интервалом распределяются по нему равномерно,
+// а после перезапуска сохраняют прежние моменты запуска.
+type intervalSchedule struct {
+	interval time.Duration
+	phase    time.Duration
+}
+
+// next возвращает ближайший слот не раньше чем через половину интервала после prev: так запуск,
+// записанный с задержкой выполнения, и внеочередной запуск после старта не сдвигают расписание
+// и не приводят к двум запускам подряд.
+func (s intervalSchedule) next(prev time.Time) time.Time {
+	earliest := time.Duration(prev.UnixNano()) + s.interval/2 - s.phase
+	slot := (earliest + s.interval - 1) / s.interval
+	return time.Unix(0, int64(slot*s.interval+s.phase))
+}
+
+// first продолжает расписание от последнего выполнения, а если очередной запуск уже пропущен
+// или проверка ещё не выполнялась — запускает её в течение startupSpread (но не позже интервала)
+// со сдвигом, зависящим от ID проверки.
+func (s intervalSchedule) first(checkID int, lastRun, now time.Time) time.Time {
+	if !lastRun.IsZero() {
+		if next := s.next(lastRun); next.After(now) {
+			return next
+		}
+	}
+	return now.Add(checkPhase(checkID, min(startupSpread, s.interval)))
+}
+
+// cronSchedule запускает проверку по cron-выражению со сдвигом jitter внутри минуты,
+// чтобы проверки с одинаковым расписанием не запускались одновременно.
+type cronSchedule struct {
+	schedule *cron.Schedule
+	location *time.Location
+	jitter   time.Duration
+}
+
+func (s cronSchedule) next(prev time.Time) time.Time {
+	occurrence := s.schedule.Next(prev.Add(-s.jitter).In(s.location))
+	if occurrence.IsZero() {
+		return occurrence
+	}
+	return occurrence.Add(s.jitter)
+}
+
+// first не догоняет пропущенные срабатывания: расписание может ограничивать время проверок
+// (например, рабочими часами), поэтому проверка ждёт следующего срабатывания.
+func (s cronSchedule) first(_ int, _, now time.Time) time.Time {
+	return s.next(now)
+}
+
+// validateSchedule проверяет cron-выражение и часовой пояс проверки.
+func validateSchedule(params models.CheckParams) error {
+	if params.Cron == "" {
+		if params.CronTimezone != "" {
+			return fmt.Errorf("cron_timezone requires cron")
+		}
+		return nil
+	}
+	if _, err := cron.Parse(params.Cron); err != nil {
+		return fmt.Errorf("invalid cron: %w", err)
+	}
+	if params.CronTimezone != "" {
+		if _, err := time.LoadLocation(params.CronTimezone); err != nil {
+			return fmt.Errorf("unknown cron_timezone %q", params.CronTimezone)
+		}
+	}
+	return nil
+}
+
+func newCheckSchedule(check models.Check) (checkSchedule, error) {
+	if check.Params.Cron == "" {
+		interval := time.Duration(check.IntervalSeconds) * time.Second
+		if interval <= 0 {
+			interval = 60 * time.Second
+		}
+		return intervalSchedule{interval: interval, phase: checkPhase(check.ID, interval)}, nil
+	}
+
+	schedule, err := cron.Parse(check.Params.Cron)
+	if err != nil {
+		return nil, fmt.Errorf("invalid cron: %w", err)
+	}
+	location := time.UTC
+	if check.Params.CronTimezone != "" {
+		if location, err = time.LoadLocation(check.Params.CronTimezone); err != nil {
+			return nil, fmt.Errorf("unknown cron_timezone %q", check.Params.CronTimezone)
+		}
+	}
+	return cronSchedule{schedule: schedule, location: location, jitter: checkPhase(check.ID, time.Minute)}, nil
+}
+
+// checkPhase детерминированно выбирает сдвиг проверки в пределах span.
+func checkPhase(checkID int, span time.Duration) time.Duration {
+	if span <= 0 {
+		return 0
+	}
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(strconv.Itoa(checkID)))
+	return time.Duration(h.Sum64() % uint64(span))
+}
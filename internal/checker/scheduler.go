@@ -5,6 +5,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/MimoJanra/DomainPulse/internal/events"
 	"github.com/MimoJanra/DomainPulse/internal/models"
 	"github.com/MimoJanra/DomainPulse/internal/storage"
 )
@@ -15,9 +16,10 @@ type Scheduler struct {
 	resultRepo       *storage.ResultRepo
 	notificationRepo *storage.NotificationRepo
 	workerPool       *WorkerPool
-	tickers          map[int]*time.Ticker
+	scheduledLoops   map[int]chan struct{}
+	bus              *events.Bus
 	realtimeLoops    map[int]chan struct{}
-	tlsLoops         map[int]chan struct{}
+	persistentLoops  map[int]chan struct{}
 	rateLimiters     map[int]*RateLimiter
 	stopChan         chan struct{}
 	mu               sync.RWMutex
@@ -29,9 +31,14 @@ func NewScheduler(
 	domainRepo *storage.SQLiteDomainRepo,
 	resultRepo *storage.ResultRepo,
 	notificationRepo *storage.NotificationRepo,
-	workerCount int,
+	stateRepo *storage.CheckStateRepo,
+	incidentRepo *storage.IncidentRepo,
+	dependencyRepo *storage.DependencyRepo,
+	maintenanceRepo *storage.MaintenanceRepo,
+	bus *events.Bus,
+	poolOptions PoolOptions,
 ) *Scheduler {
-	workerPool := NewWorkerPool(workerCount, domainRepo, resultRepo, notificationRepo)
+	workerPool := NewWorkerPool(poolOptions, domainRepo, resultRepo, notificationRepo, stateRepo, incidentRepo, dependencyRepo, maintenanceRepo)
 	workerPool.Start()
 
 	return &Scheduler{
@@ -40,9 +47,10 @@ func NewScheduler(
 		resultRepo:       resultRepo,
 		notificationRepo: notificationRepo,
 		workerPool:       workerPool,
-		tickers:          make(map[int]*time.Ticker),
+		bus:              bus,
+		scheduledLoops:   make(map[int]chan struct{}),
 		realtimeLoops:    make(map[int]chan struct{}),
-		tlsLoops:         make(map[int]chan struct{}),
+		persistentLoops:  make(map[int]chan struct{}),
 		rateLimiters:     make(map[int]*RateLimiter),
 		stopChan:         make(chan struct{}),
 	}
@@ -59,9 +67,10 @@ func (s *Scheduler) Start() {
 
 	log.Println("Scheduler started")
 
+	sub := s.bus.Subscribe(100)
 	s.loadAndScheduleChecks()
 
-	go s.watchForChanges()
+	go s.watchEvents(sub)
 }
 
 func (s *Scheduler) Stop() {
@@ -75,18 +84,18 @@ func (s *Scheduler) Stop() {
 	s.running = false
 	close(s.stopChan)
 
-	for id, ticker := range s.tickers {
-		ticker.Stop()
-		delete(s.tickers, id)
+	for id, stopChan := range s.scheduledLoops {
+		close(stopChan)
+		delete(s.scheduledLoops, id)
 	}
 
 	for id, stopChan := range s.realtimeLoops {
 		close(stopChan)
 		delete(s.realtimeLoops, id)
 	}
-	for id, ch := range s.tlsLoops {
+	for id, ch := range s.persistentLoops {
 		close(ch)
-		delete(s.tlsLoops, id)
+		delete(s.persistentLoops, id)
 	}
 
 	s.workerPool.Stop()
@@ -94,6 +103,16 @@ func (s *Scheduler) Stop() {
 	log.Println("Scheduler stopped")
 }
 
+// Metrics возвращает снимок метрик worker pool для экспорта.
+func (s *Scheduler) Metrics() PoolMetrics {
+	return s.workerPool.Metrics()
+}
+
+// QueueStats возвращает состояние очереди задач worker pool.
+func (s *Scheduler) QueueStats() models.QueueStats {
+	return s.workerPool.QueueStats()
+}
+
 func (s *Scheduler) SetWorkerCount(count int) {
 	s.workerPool.SetWorkers(count)
 	log.Printf("Worker count set to %d", count)
@@ -117,37 +136,28 @@ func (s *Scheduler) loadAndScheduleChecks() {
 }
 
 func (s *Scheduler) scheduleCheck(check models.Check) {
-	if ticker, exists := s.tickers[check.ID]; exists {
-		ticker.Stop()
-		delete(s.tickers, check.ID)
-	}
-	if stopChan, exists := s.realtimeLoops[check.ID]; exists {
-		close(stopChan)
-		delete(s.realtimeLoops, check.ID)
-	}
-	if ch, exists := s.tlsLoops[check.ID]; exists {
-		close(ch)
-		delete(s.tlsLoops, check.ID)
+	s.unscheduleCheck(check.ID)
+
+	c, ok := Lookup(check.Type)
+	if !ok {
+		log.Printf("unsupported check type: %s for check %d", check.Type, check.ID)
+		return
 	}
 
-	if check.Type == "tls" {
+	if pc, ok := c.(PersistentChecker); ok {
 		domain, err := s.domainRepo.GetByID(check.DomainID)
 		if err != nil {
-			log.Printf("domain not found for TLS check %d: %v", check.ID, err)
+			log.Printf("domain not found for %s check %d: %v", check.Type, check.ID, err)
 			return
 		}
-		if check.Params.Port <= 0 {
-			log.Printf("invalid port for TLS check %d", check.ID)
+		if err := pc.Validate(&check.Params); err != nil {
+			log.Printf("invalid params for %s check %d: %v", check.Type, check.ID, err)
 			return
 		}
 		stopChan := make(chan struct{})
-		s.tlsLoops[check.ID] = stopChan
-		timeout := 10 * time.Second
-		if check.Params.TimeoutMS > 0 {
-			timeout = time.Duration(check.Params.TimeoutMS) * time.Millisecond
-		}
+		s.persistentLoops[check.ID] = stopChan
 		job := CheckJob{Check: check, Domain: domain}
-		go s.runTLSPersistentLoop(job, timeout, stopChan)
+		go s.runPersistentLoop(pc, job, CheckTimeout(check), stopChan)
 		return
 	}
 
@@ -165,22 +175,46 @@ func (s *Scheduler) scheduleCheck(check models.Check) {
 
 		go s.runRealtimeLoop(check, stopChan)
 	} else {
-		interval := time.Duration(check.IntervalSeconds) * time.Second
-		ticker := time.NewTicker(interval)
-		s.tickers[check.ID] = ticker
-
-		go s.runCheck(check)
-
-		go func(c models.Check, t *time.Ticker) {
-			for {
-				select {
-				case <-t.C:
-					s.runCheck(c)
-				case <-s.stopChan:
-					return
-				}
-			}
-		}(check, ticker)
+		schedule, err := newCheckSchedule(check)
+		if err != nil {
+			log.Printf("invalid schedule for check %d: %v", check.ID, err)
+			return
+		}
+		stopChan := make(chan struct{})
+		s.scheduledLoops[check.ID] = stopChan
+
+		go s.runScheduledLoop(check, schedule, stopChan)
+	}
+}
+
+// runScheduledLoop запускает проверку по интервалу или cron-выражению. Первый запуск продолжает
+// расписание от последнего сохранённого результата, поэтому после перезапуска сервера проверки
+// не запускаются все одновременно.
+func (s *Scheduler) runScheduledLoop(check models.Check, schedule checkSchedule, stopChan chan struct{}) {
+	lastRun, _, err := s.resultRepo.GetLastRunTime(check.ID)
+	if err != nil {
+		log.Printf("failed to get last run time for check %d: %v", check.ID, err)
+	}
+
+	next := schedule.first(check.ID, lastRun, time.Now())
+	for {
+		if next.IsZero() {
+			log.Printf("check %d: cron %q has no upcoming runs", check.ID, check.Params.Cron)
+			return
+		}
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-timer.C:
+			s.runCheck(check)
+			next = schedule.next(time.Now())
+		case <-stopChan:
+			timer.Stop()
+			return
+		case <-s.stopChan:
+			timer.Stop()
+			return
+		}
 	}
 }
 
@@ -203,11 +237,11 @@ func (s *Scheduler) runRealtimeCheck(check models.Check) {
 	s.runCheck(check)
 }
 
-func (s *Scheduler) runTLSPersistentLoop(job CheckJob, timeout time.Duration, stopChan chan struct{}) {
+func (s *Scheduler) runPersistentLoop(pc PersistentChecker, job CheckJob, timeout time.Duration, stopChan chan struct{}) {
 	onEvent := func(result CheckResult) {
-		s.workerPool.SubmitTLSEvent(job, result)
+		s.workerPool.SubmitEvent(job, result)
 	}
-	RunTLSPersistentLoop(job.Domain.Name, job.Check.Params.Port, timeout, onEvent, stopChan)
+	pc.RunPersistent(job, timeout, onEvent, stopChan)
 }
 
 func (s *Scheduler) waitForGlobalRateLimit() {
@@ -264,104 +298,67 @@ func (s *Scheduler) runCheck(check models.Check) {
 	s.workerPool.Submit(job)
 }
 
-func (s *Scheduler) watchForChanges() {
-	ticker := time.NewTicker(30 * time.Second)
-	defer ticker.Stop()
+// watchEvents перепланирует проверки сразу после их создания, изменения, включения,
+// выключения или удаления через API.
+func (s *Scheduler) watchEvents(sub *events.Subscription) {
+	defer s.bus.Unsubscribe(sub)
 
 	for {
 		select {
-		case <-ticker.C:
-			s.updateSchedule()
+		case ev := <-sub.Events:
+			s.applyCheckEvent(ev)
 		case <-s.stopChan:
 			return
 		}
 	}
 }
 
-func (s *Scheduler) updateSchedule() {
-	checks, err := s.checkRepo.GetAll(nil)
+// applyCheckEvent читает актуальную конфигурацию проверки и планирует её заново,
+// чтобы новые интервал, параметры и таймаут применялись без перезапуска.
+func (s *Scheduler) applyCheckEvent(ev events.CheckEvent) {
+	if ev.Type == events.CheckDeleted {
+		s.mu.Lock()
+		s.unscheduleCheck(ev.CheckID)
+		s.mu.Unlock()
+		s.workerPool.forgetCheck(ev.CheckID)
+		return
+	}
+
+	check, err := s.checkRepo.GetByID(ev.CheckID)
 	if err != nil {
-		log.Printf("failed to reload checks: %v", err)
+		log.Printf("failed to reload check %d after %s event: %v", ev.CheckID, ev.Type, err)
+		s.mu.Lock()
+		s.unscheduleCheck(ev.CheckID)
+		s.mu.Unlock()
+		s.workerPool.forgetCheck(ev.CheckID)
 		return
 	}
 
 	s.mu.Lock()
 	defer s.mu.Unlock()
-
-	currentCheckIDs := s.processChecks(checks)
-	s.cleanupRemovedChecks(currentCheckIDs)
-}
-
-func (s *Scheduler) processChecks(checks []models.Check) map[int]bool {
-	currentCheckIDs := make(map[int]bool)
-
-	for _, check := range checks {
-		currentCheckIDs[check.ID] = true
-
-		if check.Enabled {
-			if s.checkNeedsUpdate(check) {
-				s.scheduleCheck(check)
-			}
-		} else {
-			s.unscheduleCheck(check.ID)
-		}
-	}
-
-	return currentCheckIDs
-}
-
-func (s *Scheduler) checkNeedsUpdate(check models.Check) bool {
-	hasTicker := s.tickers[check.ID] != nil
-	hasRealtimeLoop := s.realtimeLoops[check.ID] != nil
-	hasTLSLoop := s.tlsLoops[check.ID] != nil
-
-	if check.Type == "tls" {
-		return !hasTLSLoop
-	}
-	if hasTLSLoop {
-		return true
-	}
-	if hasTicker && check.RealtimeMode {
-		return true
+	if !s.running {
+		return
 	}
-	if hasRealtimeLoop && !check.RealtimeMode {
-		return true
+	if check.Enabled {
+		s.scheduleCheck(check)
+	} else {
+		s.unscheduleCheck(check.ID)
+		s.workerPool.forgetCheck(check.ID)
 	}
-	return !hasTicker && !hasRealtimeLoop
 }
 
 func (s *Scheduler) unscheduleCheck(checkID int) {
-	if ticker, exists := s.tickers[checkID]; exists {
-		ticker.Stop()
-		delete(s.tickers, checkID)
+	if stopChan, exists := s.scheduledLoops[checkID]; exists {
+		close(stopChan)
+		delete(s.scheduledLoops, checkID)
 	}
 	if stopChan, exists := s.realtimeLoops[checkID]; exists {
 		close(stopChan)
 		delete(s.realtimeLoops, checkID)
 	}
-	if ch, exists := s.tlsLoops[checkID]; exists {
+	if ch, exists := s.persistentLoops[checkID]; exists {
 		close(ch)
-		delete(s.tlsLoops, checkID)
-	}
-}
-
-func (s *Scheduler) cleanupRemovedChecks(currentCheckIDs map[int]bool) {
-	for id, ticker := range s.tickers {
-		if !currentCheckIDs[id] {
-			ticker.Stop()
-			delete(s.tickers, id)
-		}
-	}
-	for id, stopChan := range s.realtimeLoops {
-		if !currentCheckIDs[id] {
-			close(stopChan)
-			delete(s.realtimeLoops, id)
-		}
-	}
-	for id, ch := range s.tlsLoops {
-		if !currentCheckIDs[id] {
-			close(ch)
-			delete(s.tlsLoops, id)
-		}
+		delete(s.persistentLoops, checkID)
 	}
+	delete(s.rateLimiters, checkID)
 }
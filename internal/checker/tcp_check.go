@@ -1,14 +1,74 @@
 package checker
 
 import (
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"strconv"
 	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
 )
 
+// TCPOptions — payload для отправки после подключения и, если ReadResponse, ожидание ответа.
+type TCPOptions struct {
+	Payload      []byte
+	ReadResponse bool
+	Expect       ResponseExpectation
+}
+
+type tcpChecker struct{}
+
+func (tcpChecker) Type() string { return "tcp" }
+
+func (tcpChecker) Validate(params *models.CheckParams) error {
+	if err := validatePort(params.Port, "tcp"); err != nil {
+		return err
+	}
+	_, err := tcpOptionsFromParams(*params)
+	return err
+}
+
+func (tcpChecker) Run(job CheckJob, timeout time.Duration) (CheckResult, error) {
+	if err := validatePort(job.Check.Params.Port, "tcp"); err != nil {
+		return CheckResult{}, err
+	}
+	opts, err := tcpOptionsFromParams(job.Check.Params)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	return RunTCPCheckWithOptions(job.Domain.Name, job.Check.Params.Port, opts, timeout), nil
+}
+
+func tcpOptionsFromParams(params models.CheckParams) (TCPOptions, error) {
+	payload, err := decodePayload(params.Payload, params.PayloadEncoding)
+	if err != nil {
+		return TCPOptions{}, err
+	}
+	expect, readResponse, err := responseExpectationFromParams(params)
+	if err != nil {
+		return TCPOptions{}, err
+	}
+	return TCPOptions{
+		Payload:      payload,
+		ReadResponse: readResponse,
+		Expect:       expect,
+	}, nil
+}
+
+func validatePort(port int, checkType string) error {
+	if port <= 0 || port > 65535 {
+		return errors.New("port is required for " + checkType + " check")
+	}
+	return nil
+}
+
 func RunTCPCheckWithPayload(host string, port int, payload string, timeout time.Duration) CheckResult {
+	return RunTCPCheckWithOptions(host, port, TCPOptions{Payload: []byte(payload)}, timeout)
+}
+
+func RunTCPCheckWithOptions(host string, port int, opts TCPOptions, timeout time.Duration) CheckResult {
 	start := time.Now()
 
 	address := net.JoinHostPort(host, strconv.Itoa(port))
@@ -36,8 +96,11 @@ func RunTCPCheckWithPayload(host string, port int, payload string, timeout time.
 		}
 	}()
 
-	if payload != "" {
-		if _, writeErr := conn.Write([]byte(payload)); writeErr != nil {
+	if len(opts.Payload) > 0 {
+		if err := conn.SetWriteDeadline(start.Add(timeout)); err != nil {
+			log.Printf("failed to set TCP write deadline: %v", err)
+		}
+		if _, writeErr := conn.Write(opts.Payload); writeErr != nil {
 			return CheckResult{
 				Status:       "error",
 				DurationMS:   int(duration),
@@ -47,6 +110,10 @@ func RunTCPCheckWithPayload(host string, port int, payload string, timeout time.
 		}
 	}
 
+	if opts.ReadResponse {
+		return readTCPResponse(conn, opts.Expect, start, timeout, int(duration))
+	}
+
 	return CheckResult{
 		Status:       "success",
 		DurationMS:   int(duration),
@@ -55,6 +122,52 @@ func RunTCPCheckWithPayload(host string, port int, payload string, timeout time.
 	}
 }
 
+// readTCPResponse читает и проверяет ответ. DurationMS включает время до получения ответа,
+// время установки соединения сохраняется в details.connect_ms.
+func readTCPResponse(conn net.Conn, expect ResponseExpectation, start time.Time, timeout time.Duration, connectMS int) CheckResult {
+	data, err := readStreamResponse(conn, expect, responseDeadline(expect, start, timeout))
+	duration := int(time.Since(start).Milliseconds())
+	details := responseDetails(data)
+	details["connect_ms"] = connectMS
+
+	if err != nil {
+		if isNetworkTimeout(err) {
+			return CheckResult{
+				Status:       "timeout",
+				DurationMS:   duration,
+				Outcome:      "no_response",
+				ErrorMessage: "no response received before read deadline",
+				Details:      details,
+			}
+		}
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   duration,
+			Outcome:      "error",
+			ErrorMessage: fmt.Sprintf("TCP read failed: %v", err),
+			Details:      details,
+		}
+	}
+
+	if !expect.Match(data) {
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   duration,
+			Outcome:      "response_mismatch",
+			ErrorMessage: fmt.Sprintf("response does not match expected %s", expect),
+			Details:      details,
+		}
+	}
+
+	return CheckResult{
+		Status:       "success",
+		DurationMS:   duration,
+		Outcome:      "success",
+		ErrorMessage: "",
+		Details:      details,
+	}
+}
+
 func isNetworkTimeout(err error) bool {
 	if err == nil {
 		return false
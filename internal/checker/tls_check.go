@@ -2,17 +2,101 @@ package checker
 
 import (
 	"crypto/tls"
+	"crypto/x509"
+	"errors"
 	"fmt"
 	"log"
 	"net"
 	"strconv"
 	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
 )
 
+// TLSOptions — параметры проверки сертификата: проверка цепочки (по системному
+// или собственному набору CA) и пороги срока действия в днях.
+type TLSOptions struct {
+	VerifyChain    bool
+	RootCAs        *x509.CertPool
+	ExpiryWarnDays int
+	ExpiryFailDays int
+}
+
+type tlsChecker struct{}
+
+func (tlsChecker) Type() string { return "tls" }
+
+func (tlsChecker) Validate(params *models.CheckParams) error {
+	if err := validatePort(params.Port, "tls"); err != nil {
+		return err
+	}
+	if params.CertExpiryWarnDays < 0 || params.CertExpiryFailDays < 0 {
+		return errors.New("cert expiry thresholds must be >= 0")
+	}
+	if params.CABundle != "" {
+		if _, err := loadCABundle(params.CABundle); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (tlsChecker) Run(job CheckJob, timeout time.Duration) (CheckResult, error) {
+	if err := validatePort(job.Check.Params.Port, "tls"); err != nil {
+		return CheckResult{}, err
+	}
+	opts, err := tlsOptionsFromParams(job.Check.Params)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	return RunTLSCheckWithOptions(job.Domain.Name, job.Check.Params.Port, opts, timeout), nil
+}
+
+func (tlsChecker) RunPersistent(job CheckJob, timeout time.Duration, onEvent func(CheckResult), stopChan chan struct{}) {
+	opts, err := tlsOptionsFromParams(job.Check.Params)
+	if err != nil {
+		log.Printf("invalid tls options for check %d: %v", job.Check.ID, err)
+		return
+	}
+	RunTLSPersistentLoop(job.Domain.Name, job.Check.Params.Port, opts, timeout, onEvent, stopChan)
+}
+
+func tlsOptionsFromParams(params models.CheckParams) (TLSOptions, error) {
+	opts := TLSOptions{
+		VerifyChain:    params.VerifyCertificate,
+		ExpiryWarnDays: params.CertExpiryWarnDays,
+		ExpiryFailDays: params.CertExpiryFailDays,
+	}
+	if params.CABundle != "" {
+		pool, err := loadCABundle(params.CABundle)
+		if err != nil {
+			return TLSOptions{}, err
+		}
+		opts.RootCAs = pool
+	}
+	return opts, nil
+}
+
+// loadCABundle принимает только PEM целиком: пути к файлам не поддерживаются, чтобы через
+// параметры проверки нельзя было читать файлы сервера или узнавать, существуют ли они.
+func loadCABundle(bundle string) (*x509.CertPool, error) {
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM([]byte(bundle)) {
+		return nil, errors.New("ca_bundle contains no valid PEM certificates")
+	}
+	return pool, nil
+}
+
 func RunTLSCheck(host string, port int, timeout time.Duration) CheckResult {
-	return runTLSCheckWithSNI(host, host, port, timeout)
+	return RunTLSCheckWithOptions(host, port, TLSOptions{}, timeout)
 }
 
+func RunTLSCheckWithOptions(host string, port int, opts TLSOptions, timeout time.Duration) CheckResult {
+	return runTLSCheckWithSNI(host, host, port, opts, timeout)
+}
+
+// tlsConfigForHost всегда пропускает встроенную проверку: цепочка и имя проверяются
+// отдельно в evaluateTLSState, чтобы данные сертификата попали в результат даже при ошибке.
 func tlsConfigForHost(serverName string) *tls.Config {
 	cfg := &tls.Config{InsecureSkipVerify: true}
 	if serverName != "" && net.ParseIP(serverName) == nil {
@@ -21,7 +105,7 @@ func tlsConfigForHost(serverName string) *tls.Config {
 	return cfg
 }
 
-func runTLSCheckWithSNI(host, serverName string, port int, timeout time.Duration) CheckResult {
+func runTLSCheckWithSNI(host, serverName string, port int, opts TLSOptions, timeout time.Duration) CheckResult {
 	start := time.Now()
 	address := net.JoinHostPort(host, strconv.Itoa(port))
 
@@ -49,15 +133,115 @@ func runTLSCheckWithSNI(host, serverName string, port int, timeout time.Duration
 		}
 	}()
 
+	result := evaluateTLSState(conn.ConnectionState(), serverName, opts, time.Now())
+	result.DurationMS = int(duration)
+	return result
+}
+
+// evaluateTLSState проверяет цепочку, имя хоста и срок действия leaf-сертификата
+// и собирает сведения о сертификате и согласованных параметрах соединения.
+func evaluateTLSState(state tls.ConnectionState, serverName string, opts TLSOptions, now time.Time) CheckResult {
+	details := map[string]any{
+		"tls_version":  tls.VersionName(state.Version),
+		"cipher_suite": tls.CipherSuiteName(state.CipherSuite),
+	}
+
+	if len(state.PeerCertificates) == 0 {
+		return CheckResult{
+			Status:       "error",
+			Outcome:      "cert_missing",
+			ErrorMessage: "server presented no certificate",
+			Details:      details,
+		}
+	}
+
+	leaf := state.PeerCertificates[0]
+	daysLeft := int(leaf.NotAfter.Sub(now).Hours() / 24)
+	sans := append([]string{}, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		sans = append(sans, ip.String())
+	}
+	details["subject"] = leaf.Subject.String()
+	details["issuer"] = leaf.Issuer.String()
+	details["not_before"] = leaf.NotBefore.UTC().Format(time.RFC3339)
+	details["not_after"] = leaf.NotAfter.UTC().Format(time.RFC3339)
+	details["days_left"] = daysLeft
+	details["sans"] = sans
+
+	if opts.VerifyChain {
+		if outcome, err := verifyTLSChain(state.PeerCertificates, serverName, opts.RootCAs, now); err != nil {
+			return CheckResult{
+				Status:       "error",
+				Outcome:      outcome,
+				ErrorMessage: fmt.Sprintf("certificate verification failed: %v", err),
+				Details:      details,
+			}
+		}
+		details["verified"] = true
+	}
+
+	if now.After(leaf.NotAfter) && (opts.VerifyChain || opts.ExpiryFailDays > 0 || opts.ExpiryWarnDays > 0) {
+		return CheckResult{
+			Status:       "error",
+			Outcome:      "cert_expired",
+			ErrorMessage: fmt.Sprintf("certificate expired on %s", leaf.NotAfter.UTC().Format(time.RFC3339)),
+			Details:      details,
+		}
+	}
+	if opts.ExpiryFailDays > 0 && daysLeft < opts.ExpiryFailDays {
+		return CheckResult{
+			Status:       "error",
+			Outcome:      "cert_expiring",
+			ErrorMessage: fmt.Sprintf("certificate expires in %d days (threshold %d)", daysLeft, opts.ExpiryFailDays),
+			Details:      details,
+		}
+	}
+	if opts.ExpiryWarnDays > 0 && daysLeft < opts.ExpiryWarnDays {
+		return CheckResult{
+			Status:       "success",
+			Outcome:      "cert_expiring_soon",
+			ErrorMessage: fmt.Sprintf("certificate expires in %d days (warning threshold %d)", daysLeft, opts.ExpiryWarnDays),
+			Details:      details,
+		}
+	}
+
 	return CheckResult{
 		Status:       "success",
-		DurationMS:   int(duration),
 		Outcome:      "success",
 		ErrorMessage: "",
+		Details:      details,
+	}
+}
+
+func verifyTLSChain(certs []*x509.Certificate, serverName string, roots *x509.CertPool, now time.Time) (string, error) {
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+
+	leaf := certs[0]
+	verifyOpts := x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+		CurrentTime:   now,
+	}
+	if _, err := leaf.Verify(verifyOpts); err != nil {
+		var invalidErr x509.CertificateInvalidError
+		if errors.As(err, &invalidErr) && invalidErr.Reason == x509.Expired {
+			return "cert_expired", err
+		}
+		return "cert_untrusted", err
 	}
+
+	if serverName != "" {
+		if err := leaf.VerifyHostname(serverName); err != nil {
+			return "cert_hostname_mismatch", err
+		}
+	}
+	return "", nil
 }
 
-func RunTLSPersistentLoop(host string, port int, timeout time.Duration, onEvent func(CheckResult), stopChan chan struct{}) {
+func RunTLSPersistentLoop(host string, port int, opts TLSOptions, timeout time.Duration, onEvent func(CheckResult), stopChan chan struct{}) {
 	readTimeout := 5 * time.Minute
 	if timeout > 0 && timeout < readTimeout {
 		readTimeout = timeout
@@ -96,11 +280,28 @@ func RunTLSPersistentLoop(host string, port int, timeout time.Duration, onEvent
 		}
 
 		connectedAt := time.Now()
+		certResult := evaluateTLSState(conn.ConnectionState(), host, opts, connectedAt)
+		if certResult.Status != "success" {
+			onEvent(certResult)
+			_ = conn.Close()
+			select {
+			case <-stopChan:
+				return
+			case <-time.After(10 * time.Second):
+			}
+			continue
+		}
+
+		outcome := "connected"
+		if certResult.Outcome != "success" {
+			outcome = certResult.Outcome
+		}
 		onEvent(CheckResult{
 			Status:       "success",
 			DurationMS:   int(time.Since(connectedAt).Milliseconds()),
-			Outcome:      "connected",
-			ErrorMessage: "",
+			Outcome:      outcome,
+			ErrorMessage: certResult.ErrorMessage,
+			Details:      certResult.Details,
 		})
 
 		_ = conn.SetReadDeadline(time.Now().Add(readTimeout))
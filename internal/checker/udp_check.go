@@ -5,10 +5,65 @@ import (
 	"fmt"
 	"net"
 	"strconv"
+	"syscall"
 	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
 )
 
+// UDPOptions — параметры UDP проверки.
+// Если FailOnNoResponse == false и ожидание ответа не задано, отсутствие ответа считается успехом
+// (многие UDP сервисы не отвечают вовсе).
+type UDPOptions struct {
+	Payload          []byte
+	Expect           ResponseExpectation
+	FailOnNoResponse bool
+}
+
+type udpChecker struct{}
+
+func (udpChecker) Type() string { return "udp" }
+
+func (udpChecker) Validate(params *models.CheckParams) error {
+	if err := validatePort(params.Port, "udp"); err != nil {
+		return err
+	}
+	_, err := udpOptionsFromParams(*params)
+	return err
+}
+
+func (udpChecker) Run(job CheckJob, timeout time.Duration) (CheckResult, error) {
+	if err := validatePort(job.Check.Params.Port, "udp"); err != nil {
+		return CheckResult{}, err
+	}
+	opts, err := udpOptionsFromParams(job.Check.Params)
+	if err != nil {
+		return CheckResult{}, err
+	}
+	return RunUDPCheckWithOptions(job.Domain.Name, job.Check.Params.Port, opts, timeout), nil
+}
+
+func udpOptionsFromParams(params models.CheckParams) (UDPOptions, error) {
+	payload, err := decodePayload(params.Payload, params.PayloadEncoding)
+	if err != nil {
+		return UDPOptions{}, err
+	}
+	expect, _, err := responseExpectationFromParams(params)
+	if err != nil {
+		return UDPOptions{}, err
+	}
+	return UDPOptions{
+		Payload:          payload,
+		Expect:           expect,
+		FailOnNoResponse: params.FailOnNoResponse,
+	}, nil
+}
+
 func RunUDPCheck(host string, port int, payload string, timeout time.Duration) CheckResult {
+	return RunUDPCheckWithOptions(host, port, UDPOptions{Payload: []byte(payload)}, timeout)
+}
+
+func RunUDPCheckWithOptions(host string, port int, opts UDPOptions, timeout time.Duration) CheckResult {
 	start := time.Now()
 
 	address := net.JoinHostPort(host, strconv.Itoa(port))
@@ -31,24 +86,17 @@ func RunUDPCheck(host string, port int, payload string, timeout time.Duration) C
 	}
 	defer conn.Close()
 
-	if err := conn.SetReadDeadline(time.Now().Add(timeout)); err != nil {
-		duration := time.Since(start).Milliseconds()
-		return CheckResult{
-			Status:       "error",
-			DurationMS:   int(duration),
-			Outcome:      "error",
-			ErrorMessage: fmt.Sprintf("failed to set read deadline: %v", err),
-		}
-	}
-
-	sendData := []byte(payload)
-	if payload == "" {
+	sendData := opts.Payload
+	if len(sendData) == 0 {
 		sendData = []byte("ping")
 	}
 
 	_, err = conn.Write(sendData)
 	if err != nil {
 		duration := time.Since(start).Milliseconds()
+		if isPortUnreachable(err) {
+			return createPortUnreachableResult(int(duration), err)
+		}
 		return CheckResult{
 			Status:       "error",
 			DurationMS:   int(duration),
@@ -57,8 +105,12 @@ func RunUDPCheck(host string, port int, payload string, timeout time.Duration) C
 		}
 	}
 
-	buffer := make([]byte, 1024)
-	err = conn.SetReadDeadline(time.Now().Add(timeout))
+	maxBytes := opts.Expect.MaxBytes
+	if maxBytes <= 0 {
+		maxBytes = defaultMaxResponseBytes
+	}
+	buffer := make([]byte, maxBytes)
+	err = conn.SetReadDeadline(responseDeadline(opts.Expect, start, timeout))
 	if err != nil {
 		duration := time.Since(start).Milliseconds()
 		return CheckResult{
@@ -68,12 +120,20 @@ func RunUDPCheck(host string, port int, payload string, timeout time.Duration) C
 			ErrorMessage: fmt.Sprintf("failed to set read deadline: %v", err),
 		}
 	}
-	_, err = conn.Read(buffer)
+	n, err := conn.Read(buffer)
 	duration := time.Since(start).Milliseconds()
 
 	if err != nil {
 		var netErr net.Error
 		if errors.As(err, &netErr) && netErr.Timeout() {
+			if opts.FailOnNoResponse || opts.Expect.hasPattern() {
+				return CheckResult{
+					Status:       "timeout",
+					DurationMS:   int(duration),
+					Outcome:      "no_response",
+					ErrorMessage: "UDP packet sent but no response received",
+				}
+			}
 			return CheckResult{
 				Status:       "success",
 				DurationMS:   int(duration),
@@ -81,6 +141,9 @@ func RunUDPCheck(host string, port int, payload string, timeout time.Duration) C
 				ErrorMessage: "UDP packet sent but no response received (expected for UDP)",
 			}
 		}
+		if isPortUnreachable(err) {
+			return createPortUnreachableResult(int(duration), err)
+		}
 		return CheckResult{
 			Status:       "error",
 			DurationMS:   int(duration),
@@ -89,10 +152,37 @@ func RunUDPCheck(host string, port int, payload string, timeout time.Duration) C
 		}
 	}
 
+	data := buffer[:n]
+	if !opts.Expect.Match(data) {
+		return CheckResult{
+			Status:       "error",
+			DurationMS:   int(duration),
+			Outcome:      "response_mismatch",
+			ErrorMessage: fmt.Sprintf("response does not match expected %s", opts.Expect),
+			Details:      responseDetails(data),
+		}
+	}
+
 	return CheckResult{
 		Status:       "success",
 		DurationMS:   int(duration),
 		Outcome:      "success",
 		ErrorMessage: "",
+		Details:      responseDetails(data),
+	}
+}
+
+// isPortUnreachable распознаёт ICMP port unreachable: на подключённом UDP сокете
+// ядро возвращает его как ECONNREFUSED при следующей операции чтения или записи.
+func isPortUnreachable(err error) bool {
+	return errors.Is(err, syscall.ECONNREFUSED)
+}
+
+func createPortUnreachableResult(duration int, err error) CheckResult {
+	return CheckResult{
+		Status:       "error",
+		DurationMS:   duration,
+		Outcome:      "port_unreachable",
+		ErrorMessage: fmt.Sprintf("ICMP port unreachable: %v", err),
 	}
 }
@@ -3,7 +3,6 @@ package checker
 import (
 	"fmt"
 	"log"
-	"net"
 	"sync"
 	"time"
 
@@ -12,7 +11,7 @@ import (
 	"github.com/MimoJanra/DomainPulse/internal/storage"
 )
 
-type TLSEvent struct {
+type PersistentEvent struct {
 	Job    CheckJob
 	Result CheckResult
 }
@@ -20,16 +19,23 @@ type TLSEvent struct {
 type WorkerPool struct {
 	workers          int
 	workersMu        sync.Mutex
-	jobQueue         chan CheckJob
-	tlsEventChan     chan TLSEvent
+	queue            *jobQueue
+	eventChan        chan PersistentEvent
 	wg               sync.WaitGroup
 	stopChan         chan struct{}
 	domainRepo       *storage.SQLiteDomainRepo
 	resultRepo       *storage.ResultRepo
 	notificationRepo *storage.NotificationRepo
+	incidentRepo     *storage.IncidentRepo
+	dependencyRepo   *storage.DependencyRepo
+	maintenanceRepo  *storage.MaintenanceRepo
 	notifSender      *notifications.NotificationSender
+	states           *stateTracker
 	checkMetrics     map[int]*CheckMetrics
 	metricsMu        sync.RWMutex
+	notifSent        map[string]uint64
+	notifFailures    map[string]uint64
+	notifMu          sync.Mutex
 }
 
 type CheckMetrics struct {
@@ -39,6 +45,14 @@ type CheckMetrics struct {
 	averageDuration time.Duration
 	sampleCount     int
 	lastCheckTime   time.Time
+
+	domain         string
+	checkType      string
+	hasState       bool
+	up             bool
+	lastDurationMS int
+	lastResultTime time.Time
+	results        map[ResultKey]uint64
 }
 
 type CheckJob struct {
@@ -46,17 +60,39 @@ type CheckJob struct {
 	Domain models.Domain
 }
 
-func NewWorkerPool(workers int, domainRepo *storage.SQLiteDomainRepo, resultRepo *storage.ResultRepo, notificationRepo *storage.NotificationRepo) *WorkerPool {
+// PoolOptions — параметры worker pool. Нулевые QueueSize и NotificationTimeout заменяются значениями по умолчанию.
+type PoolOptions struct {
+	Workers             int
+	QueueSize           int
+	NotificationTimeout time.Duration
+}
+
+const defaultNotificationTimeout = 10 * time.Second
+
+func NewWorkerPool(opts PoolOptions, domainRepo *storage.SQLiteDomainRepo, resultRepo *storage.ResultRepo, notificationRepo *storage.NotificationRepo, stateRepo *storage.CheckStateRepo, incidentRepo *storage.IncidentRepo, dependencyRepo *storage.DependencyRepo, maintenanceRepo *storage.MaintenanceRepo) *WorkerPool {
+	if opts.QueueSize <= 0 {
+		opts.QueueSize = jobQueueCapacity
+	}
+	if opts.NotificationTimeout <= 0 {
+		opts.NotificationTimeout = defaultNotificationTimeout
+	}
+
 	return &WorkerPool{
-		workers:          workers,
-		jobQueue:         make(chan CheckJob, 100),
-		tlsEventChan:     make(chan TLSEvent, 50),
+		workers:          opts.Workers,
+		queue:            newJobQueue(opts.QueueSize),
+		eventChan:        make(chan PersistentEvent, 50),
 		stopChan:         make(chan struct{}),
 		domainRepo:       domainRepo,
 		resultRepo:       resultRepo,
 		notificationRepo: notificationRepo,
-		notifSender:      notifications.NewNotificationSender(),
+		incidentRepo:     incidentRepo,
+		dependencyRepo:   dependencyRepo,
+		maintenanceRepo:  maintenanceRepo,
+		notifSender:      notifications.NewNotificationSender(opts.NotificationTimeout),
+		states:           newStateTracker(stateRepo),
 		checkMetrics:     make(map[int]*CheckMetrics),
+		notifSent:        make(map[string]uint64),
+		notifFailures:    make(map[string]uint64),
 	}
 }
 
@@ -66,40 +102,83 @@ func (wp *WorkerPool) Start() {
 		go wp.worker(i)
 	}
 	wp.wg.Add(1)
-	go wp.tlsEventProcessor()
+	go wp.eventProcessor()
 }
 
 func (wp *WorkerPool) Stop() {
 	close(wp.stopChan)
-	close(wp.tlsEventChan)
-	close(wp.jobQueue)
+	close(wp.eventChan)
+	wp.queue.close()
 	wp.wg.Wait()
 }
 
+// Submit ставит проверку в очередь. Недоступные и просроченные проверки получают более высокий приоритет;
+// если очередь заполнена, отброшенный запуск записывается в историю со статусом skipped.
 func (wp *WorkerPool) Submit(job CheckJob) {
-	select {
-	case wp.jobQueue <- job:
-	case <-wp.stopChan:
-		return
-	default:
-		log.Printf("worker pool queue full, dropping job for check %d", job.Check.ID)
+	dropped, shed := wp.queue.push(job, wp.jobPriority(job))
+	if shed {
+		wp.recordSkipped(dropped)
+	}
+}
+
+func (wp *WorkerPool) jobPriority(job CheckJob) int {
+	if wp.states.isDown(job.Check.ID) {
+		return priorityFailing
+	}
+
+	metrics := wp.getOrCreateMetrics(job.Check.ID)
+	metrics.mu.Lock()
+	lastCheck := metrics.lastCheckTime
+	metrics.mu.Unlock()
+
+	interval := time.Duration(job.Check.IntervalSeconds) * time.Second
+	if !lastCheck.IsZero() && interval > 0 && time.Since(lastCheck) > 2*interval {
+		return priorityOverdue
+	}
+	return priorityNormal
+}
+
+// recordSkipped сохраняет результат skipped, чтобы пропуск запуска из-за перегрузки
+// можно было отличить в истории от недоступности.
+func (wp *WorkerPool) recordSkipped(job CheckJob) {
+	log.Printf("worker pool queue full, skipping run of check %d", job.Check.ID)
+
+	res := models.Result{
+		CheckID:      job.Check.ID,
+		Status:       storage.StatusSkipped,
+		Outcome:      "queue_full",
+		ErrorMessage: "job queue is full",
+		CreatedAt:    time.Now().Format(time.RFC3339),
+	}
+	if _, err := wp.resultRepo.Add(res); err != nil {
+		log.Printf("failed to save skipped result for check %d: %v", job.Check.ID, err)
 	}
+	wp.recordResult(job, res.Status, res.Outcome, 0)
+}
+
+// QueueStats возвращает состояние очереди задач.
+func (wp *WorkerPool) QueueStats() models.QueueStats {
+	stats := wp.queue.stats()
+
+	wp.workersMu.Lock()
+	stats.Workers = wp.workers
+	wp.workersMu.Unlock()
+	return stats
 }
 
-func (wp *WorkerPool) SubmitTLSEvent(job CheckJob, result CheckResult) {
+func (wp *WorkerPool) SubmitEvent(job CheckJob, result CheckResult) {
 	select {
-	case wp.tlsEventChan <- TLSEvent{Job: job, Result: result}:
+	case wp.eventChan <- PersistentEvent{Job: job, Result: result}:
 	case <-wp.stopChan:
 	default:
-		log.Printf("tls event queue full, dropping event for check %d", job.Check.ID)
+		log.Printf("persistent check event queue full, dropping event for check %d", job.Check.ID)
 	}
 }
 
-func (wp *WorkerPool) tlsEventProcessor() {
+func (wp *WorkerPool) eventProcessor() {
 	defer wp.wg.Done()
-	for ev := range wp.tlsEventChan {
+	for ev := range wp.eventChan {
 		wp.saveResult(ev.Job, ev.Result, 0)
-		wp.sendNotifications(ev.Job, ev.Result, time.Now().Format(time.RFC3339))
 	}
 }
 
@@ -124,144 +203,185 @@ func (wp *WorkerPool) worker(_ int) {
 	defer wp.wg.Done()
 
 	for {
-		select {
-		case <-wp.stopChan:
+		job, ok := wp.queue.pop()
+		if !ok {
 			return
-		case job, ok := <-wp.jobQueue:
-			if !ok {
-				return
-			}
-			wp.executeCheck(job)
 		}
+		wp.executeCheck(job)
 	}
 }
 
 func (wp *WorkerPool) executeCheck(job CheckJob) {
 	startTime := time.Now()
-	timeout := wp.getTimeout(job.Check)
 
-	result := wp.runCheckByType(job, timeout)
-	if result == nil {
+	if window, active := wp.activeMaintenance(job, startTime); active && window.Mode == storage.MaintenanceModeSkip {
 		return
 	}
 
-	wp.saveResult(job, *result, time.Since(startTime))
-}
-
-func (wp *WorkerPool) getTimeout(check models.Check) time.Duration {
-	timeout := 10 * time.Second
-	if check.Params.TimeoutMS > 0 {
-		timeout = time.Duration(check.Params.TimeoutMS) * time.Millisecond
+	result, err := RunCheckWithRetries(job, wp.stopChan)
+	if err != nil {
+		log.Printf("check %d (%s) not executed: %v", job.Check.ID, job.Check.Type, err)
+		return
 	}
-	return timeout
+
+	wp.saveResult(job, result, time.Since(startTime))
 }
 
-func (wp *WorkerPool) runCheckByType(job CheckJob, timeout time.Duration) *CheckResult {
-	switch job.Check.Type {
-	case "http":
-		result := wp.runHTTPCheck(job, timeout)
-		return &result
-	case "icmp":
-		result := RunICMPCheck(job.Domain.Name, timeout)
-		return &result
-	case "tcp":
-		return wp.runTCPCheck(job, timeout)
-	case "udp":
-		return wp.runUDPCheck(job, timeout)
-	case "tls":
-		return wp.runTLSCheck(job, timeout)
-	default:
-		log.Printf("unsupported check type: %s for check %d", job.Check.Type, job.Check.ID)
-		return nil
+func (wp *WorkerPool) saveResult(job CheckJob, result CheckResult, duration time.Duration) {
+	if window, active := wp.activeMaintenance(job, time.Now()); active {
+		wp.saveMaintenanceResult(job, result, window)
+		return
 	}
-}
 
-func (wp *WorkerPool) runHTTPCheck(job CheckJob, timeout time.Duration) CheckResult {
-	fullURL := BuildHTTPURL(job.Domain.Name, job.Check.Params)
-	method := NormalizeHTTPMethod(job.Check.Params.Method)
-	return RunHTTPCheckWithMethodAndHeaders(fullURL, method, job.Check.Params.Body, job.Check.Params.Headers, timeout)
-}
+	suppressed := isDownStatus(result.Status) && wp.dependencyDown(job.Check.ID)
 
-func BuildHTTPURL(domainName string, params models.CheckParams) string {
-	path := params.Path
-	if path == "" {
-		path = "/"
-	}
-	scheme := params.Scheme
-	if scheme == "" {
-		scheme = "https"
-	}
-	host := domainName
-	if ip := net.ParseIP(domainName); ip != nil && ip.To4() == nil {
-		host = "[" + domainName + "]"
+	res := models.Result{
+		CheckID:      job.Check.ID,
+		Status:       result.Status,
+		StatusCode:   result.StatusCode,
+		DurationMS:   result.DurationMS,
+		Outcome:      result.Outcome,
+		ErrorMessage: result.ErrorMessage,
+		Details:      result.Details,
+		Timings:      result.Timings,
+		Attempts:     result.Attempts,
+		Suppressed:   suppressed,
+		CreatedAt:    time.Now().Format(time.RFC3339),
 	}
-	fullURL := scheme + "://" + host
-	if len(path) > 0 && path[0] != '/' {
-		fullURL += "/"
+
+	resultID, err := wp.resultRepo.Add(res)
+	if err != nil {
+		log.Printf("failed to save result for check %d: %v", job.Check.ID, err)
 	}
-	fullURL += path
-	return fullURL
-}
 
-func NormalizeHTTPMethod(method string) string {
-	if method == "" {
-		return "GET"
+	isError := result.Status == "error" || result.Status == "timeout"
+	wp.updateMetrics(job.Check.ID, duration, isError)
+	wp.recordResult(job, result.Status, result.Outcome, result.DurationMS)
+
+	if suppressed {
+		wp.states.markSuppressed(job.Check.ID)
+		return
 	}
-	return method
+
+	renotify := time.Duration(job.Check.Params.RenotifyIntervalMinutes) * time.Minute
+	event, downSince := wp.states.observe(job.Check.ID, result.Status, renotify, time.Now())
+
+	incidentID := wp.trackIncident(job, result, resultID, event, res.CreatedAt)
+	wp.sendNotifications(job, result, resultID, incidentID, res.CreatedAt, event, downSince)
 }
 
-func (wp *WorkerPool) runTCPCheck(job CheckJob, timeout time.Duration) *CheckResult {
-	port := job.Check.Params.Port
-	if port <= 0 {
-		log.Printf("invalid port for TCP check %d", job.Check.ID)
-		return nil
+// activeMaintenance возвращает окно обслуживания, действующее для проверки в момент now.
+func (wp *WorkerPool) activeMaintenance(job CheckJob, now time.Time) (models.MaintenanceWindow, bool) {
+	windows, err := wp.maintenanceRepo.GetForCheck(job.Check.ID, job.Check.DomainID)
+	if err != nil {
+		log.Printf("failed to get maintenance windows for check %d: %v", job.Check.ID, err)
+		return models.MaintenanceWindow{}, false
+	}
+	if len(windows) == 0 {
+		return models.MaintenanceWindow{}, false
 	}
-	result := RunTCPCheckWithPayload(job.Domain.Name, port, job.Check.Params.Payload, timeout)
-	return &result
+	return storage.ActiveMaintenance(storage.NewMaintenanceSchedules(windows), now)
 }
 
-func (wp *WorkerPool) runUDPCheck(job CheckJob, timeout time.Duration) *CheckResult {
-	port := job.Check.Params.Port
-	if port <= 0 {
-		log.Printf("invalid port for UDP check %d", job.Check.ID)
-		return nil
+// saveMaintenanceResult обрабатывает результат, полученный во время окна обслуживания:
+// в режиме skip он отбрасывается, в режиме mark сохраняется со статусом maintenance.
+// Состояние проверки, инциденты и уведомления при этом не затрагиваются.
+func (wp *WorkerPool) saveMaintenanceResult(job CheckJob, result CheckResult, window models.MaintenanceWindow) {
+	if window.Mode == storage.MaintenanceModeSkip {
+		return
 	}
-	result := RunUDPCheck(job.Domain.Name, port, job.Check.Params.Payload, timeout)
-	return &result
-}
 
-func (wp *WorkerPool) runTLSCheck(job CheckJob, timeout time.Duration) *CheckResult {
-	port := job.Check.Params.Port
-	if port <= 0 {
-		log.Printf("invalid port for TLS check %d", job.Check.ID)
-		return nil
+	details := make(map[string]any, len(result.Details)+2)
+	for k, v := range result.Details {
+		details[k] = v
 	}
-	result := RunTLSCheck(job.Domain.Name, port, timeout)
-	return &result
-}
+	details["actual_status"] = result.Status
+	details["maintenance_window_id"] = window.ID
 
-func (wp *WorkerPool) saveResult(job CheckJob, result CheckResult, duration time.Duration) {
 	res := models.Result{
 		CheckID:      job.Check.ID,
-		Status:       result.Status,
+		Status:       storage.StatusMaintenance,
 		StatusCode:   result.StatusCode,
 		DurationMS:   result.DurationMS,
 		Outcome:      result.Outcome,
 		ErrorMessage: result.ErrorMessage,
+		Details:      details,
+		Timings:      result.Timings,
+		Attempts:     result.Attempts,
 		CreatedAt:    time.Now().Format(time.RFC3339),
 	}
-
-	if err := wp.resultRepo.Add(res); err != nil {
+	if _, err := wp.resultRepo.Add(res); err != nil {
 		log.Printf("failed to save result for check %d: %v", job.Check.ID, err)
 	}
+	wp.recordResult(job, res.Status, res.Outcome, res.DurationMS)
+}
 
-	isError := result.Status == "error" || result.Status == "timeout"
-	wp.updateMetrics(job.Check.ID, duration, isError)
+// dependencyDown сообщает, недоступна ли хотя бы одна проверка, от которой зависит checkID.
+// Сбой такой проверки считается следствием сбоя родителя: он сохраняется с флагом suppressed,
+// не меняет состояние проверки и не вызывает уведомлений и инцидентов.
+func (wp *WorkerPool) dependencyDown(checkID int) bool {
+	parents, err := wp.dependencyRepo.GetParents(checkID)
+	if err != nil {
+		log.Printf("failed to get dependencies for check %d: %v", checkID, err)
+		return false
+	}
+	for _, parentID := range parents {
+		if wp.states.isDown(parentID) {
+			return true
+		}
+	}
+	return false
+}
+
+// trackIncident открывает инцидент при переходе проверки в down, привязывает к нему
+// последующие неуспешные результаты и закрывает при восстановлении.
+// Возвращает ID затронутого инцидента или 0.
+func (wp *WorkerPool) trackIncident(job CheckJob, result CheckResult, resultID int, event, createdAt string) int {
+	checkID := job.Check.ID
+	switch {
+	case event == AlertEventDown:
+		inc, err := wp.incidentRepo.Open(checkID, createdAt, incidentCause(result), resultID)
+		if err != nil {
+			log.Printf("failed to open incident for check %d: %v", checkID, err)
+			return 0
+		}
+		return inc.ID
+	case event == AlertEventRecovered:
+		inc, found, err := wp.incidentRepo.GetActiveByCheckID(checkID)
+		if err != nil || !found {
+			return 0
+		}
+		if err := wp.incidentRepo.Resolve(inc.ID, createdAt, "auto-resolved: check recovered"); err != nil {
+			log.Printf("failed to resolve incident %d: %v", inc.ID, err)
+		}
+		return inc.ID
+	case isDownStatus(result.Status) && resultID > 0:
+		inc, found, err := wp.incidentRepo.GetActiveByCheckID(checkID)
+		if err != nil || !found {
+			return 0
+		}
+		if err := wp.incidentRepo.AddResult(inc.ID, resultID); err != nil {
+			log.Printf("failed to link result %d to incident %d: %v", resultID, inc.ID, err)
+		}
+		return inc.ID
+	}
+	return 0
+}
 
-	wp.sendNotifications(job, result, res.CreatedAt)
+func incidentCause(result CheckResult) string {
+	if result.ErrorMessage != "" {
+		return result.ErrorMessage
+	}
+	if result.Outcome != "" {
+		return result.Outcome
+	}
+	return result.Status
 }
 
-func (wp *WorkerPool) sendNotifications(job CheckJob, result CheckResult, createdAt string) {
+// sendNotifications уведомляет только о смене состояния проверки (down, recovered)
+// и, если задан renotify_interval_minutes, повторно напоминает, пока проверка недоступна.
+// О медленном ответе канал уведомляется один раз, пока время ответа не вернётся ниже порога.
+func (wp *WorkerPool) sendNotifications(job CheckJob, result CheckResult, resultID, incidentID int, createdAt, event, downSince string) {
 	settingsList, err := wp.notificationRepo.GetEnabled()
 	if err != nil {
 		log.Printf("failed to get notification settings: %v", err)
@@ -272,48 +392,70 @@ func (wp *WorkerPool) sendNotifications(job CheckJob, result CheckResult, create
 		return
 	}
 
-	isFailure := result.Status == "error" || result.Status == "timeout"
-
 	msg := notifications.NotificationMessage{
-		CheckID:      job.Check.ID,
-		DomainName:   job.Domain.Name,
-		CheckType:    job.Check.Type,
-		Status:       result.Status,
-		ErrorMessage: result.ErrorMessage,
-		DurationMS:   result.DurationMS,
-		CreatedAt:    createdAt,
+		CheckID:       job.Check.ID,
+		DomainID:      job.Domain.ID,
+		DomainName:    job.Domain.Name,
+		CheckType:     job.Check.Type,
+		ResultID:      resultID,
+		Status:        result.Status,
+		StatusCode:    result.StatusCode,
+		Outcome:       result.Outcome,
+		Event:         event,
+		PreviousState: previousState(event, wp.states.isDown(job.Check.ID)),
+		DownSince:     downSince,
+		IncidentID:    incidentID,
+		ErrorMessage:  result.ErrorMessage,
+		DurationMS:    result.DurationMS,
+		Severity:      job.Check.Params.Severity,
+		CreatedAt:     createdAt,
 	}
 
 	for _, settings := range settingsList {
 		shouldNotify := false
 		notifySlow := false
 
-		if isFailure && settings.NotifyOnFailure {
-			shouldNotify = true
-		}
-		if !isFailure && settings.NotifyOnSuccess {
-			shouldNotify = true
+		switch event {
+		case AlertEventDown, AlertEventReminder:
+			shouldNotify = settings.NotifyOnFailure
+		case AlertEventRecovered:
+			shouldNotify = settings.NotifyOnFailure || settings.NotifyOnSuccess
 		}
 
 		if settings.NotifyOnSlowResponse && settings.SlowResponseThreshold > 0 {
-			if result.DurationMS >= settings.SlowResponseThreshold {
-				notifySlow = true
-			}
+			isSlow := result.DurationMS >= settings.SlowResponseThreshold
+			notifySlow = wp.states.slowChanged(job.Check.ID, settings.ID, isSlow)
 		}
 
 		if shouldNotify {
-			wp.notifSender.SendNotification(settings, msg)
+			wp.sendNotification(settings, msg)
 		}
 
 		if notifySlow {
 			slowMsg := msg
 			slowMsg.Status = "slow_response"
+			slowMsg.Event = ""
 			slowMsg.ErrorMessage = fmt.Sprintf("Response time %d ms exceeds threshold of %d ms", result.DurationMS, settings.SlowResponseThreshold)
-			wp.notifSender.SendNotification(settings, slowMsg)
+			wp.sendNotification(settings, slowMsg)
 		}
 	}
 }
 
+// previousState — состояние проверки до результата, вызвавшего событие event;
+// down — состояние проверки после него.
+func previousState(event string, down bool) string {
+	switch event {
+	case AlertEventDown:
+		return "up"
+	case AlertEventRecovered, AlertEventReminder:
+		return "down"
+	}
+	if down {
+		return "down"
+	}
+	return "up"
+}
+
 func (wp *WorkerPool) updateMetrics(checkID int, duration time.Duration, isError bool) {
 	metrics := wp.getOrCreateMetrics(checkID)
 
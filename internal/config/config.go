@@ -0,0 +1,373 @@
+// Package config — конфигурация сервера. Значения собираются по слоям, каждый следующий
+// переопределяет предыдущий: значения по умолчанию, файл (YAML или TOML), переменные окружения
+// DOMAINPULSE_* и флаги командной строки.
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/secrets"
+
+	"github.com/BurntSushi/toml"
+	"go.yaml.in/yaml/v3"
+)
+
+// ConfigEnv — переменная окружения с путём к файлу конфигурации (вместо флага -config).
+const ConfigEnv = "DOMAINPULSE_CONFIG"
+
+type Config struct {
+	Server        ServerConfig
+	Database      DatabaseConfig
+	Checker       CheckerConfig
+	Notifications NotificationsConfig
+	Secrets       SecretsConfig
+}
+
+type ServerConfig struct {
+	Addr            string
+	StaticDir       string
+	ShutdownTimeout time.Duration
+}
+
+type DatabaseConfig struct {
+	Path string
+}
+
+// CheckerConfig — параметры выполнения проверок. GlobalRateLimit — не больше запусков в минуту
+// на все проверки (0 — без ограничения), DefaultTimeout — таймаут проверок без timeout_ms.
+type CheckerConfig struct {
+	Workers         int
+	QueueSize       int
+	GlobalRateLimit int
+	DefaultTimeout  time.Duration
+}
+
+type NotificationsConfig struct {
+	Timeout time.Duration
+}
+
+// SecretsConfig — ключи шифрования секретов уведомлений (base64 от 32 байт). Key шифрует новые значения,
+// PreviousKeys только расшифровывают значения, записанные до смены ключа. Без Key секреты хранятся открыто.
+type SecretsConfig struct {
+	Key          string
+	PreviousKeys []string
+}
+
+func Default() Config {
+	return Config{
+		Server: ServerConfig{
+			Addr:            ":8080",
+			StaticDir:       "web",
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Database: DatabaseConfig{Path: "database.db"},
+		Checker: CheckerConfig{
+			Workers:         5,
+			QueueSize:       100,
+			GlobalRateLimit: 1000,
+			DefaultTimeout:  10 * time.Second,
+		},
+		Notifications: NotificationsConfig{Timeout: 10 * time.Second},
+	}
+}
+
+// setting — параметр конфигурации: ключ в файле, переменная окружения и флаг.
+// Параметры без флага (секреты) задаются только файлом или окружением, чтобы не попадать в список процессов.
+// restart — изменение вступает в силу только после перезапуска.
+type setting struct {
+	key     string
+	env     string
+	flag    string
+	usage   string
+	restart bool
+	set     func(c *Config, value string) error
+	get     func(c Config) string
+}
+
+var settings = []setting{
+	{key: "server.addr", env: "DOMAINPULSE_ADDR", flag: "addr", usage: "адрес HTTP сервера", restart: true,
+		set: func(c *Config, v string) error { c.Server.Addr = v; return nil },
+		get: func(c Config) string { return c.Server.Addr }},
+	{key: "server.static_dir", env: "DOMAINPULSE_STATIC_DIR", flag: "static-dir", usage: "каталог веб-интерфейса (index.html и static/)", restart: true,
+		set: func(c *Config, v string) error { c.Server.StaticDir = v; return nil },
+		get: func(c Config) string { return c.Server.StaticDir }},
+	{key: "server.shutdown_timeout", env: "DOMAINPULSE_SHUTDOWN_TIMEOUT", flag: "shutdown-timeout", usage: "время на завершение запросов при остановке",
+		set: durationSetter(func(c *Config) *time.Duration { return &c.Server.ShutdownTimeout }),
+		get: func(c Config) string { return c.Server.ShutdownTimeout.String() }},
+	{key: "database.path", env: "DOMAINPULSE_DB_PATH", flag: "db", usage: "путь к файлу базы данных SQLite", restart: true,
+		set: func(c *Config, v string) error { c.Database.Path = v; return nil },
+		get: func(c Config) string { return c.Database.Path }},
+	{key: "checker.workers", env: "DOMAINPULSE_WORKERS", flag: "workers", usage: "число воркеров, выполняющих проверки",
+		set: intSetter(func(c *Config) *int { return &c.Checker.Workers }),
+		get: func(c Config) string { return strconv.Itoa(c.Checker.Workers) }},
+	{key: "checker.queue_size", env: "DOMAINPULSE_QUEUE_SIZE", flag: "queue-size", usage: "ёмкость очереди запусков проверок", restart: true,
+		set: intSetter(func(c *Config) *int { return &c.Checker.QueueSize }),
+		get: func(c Config) string { return strconv.Itoa(c.Checker.QueueSize) }},
+	{key: "checker.global_rate_limit", env: "DOMAINPULSE_GLOBAL_RATE_LIMIT", flag: "global-rate-limit", usage: "максимум запусков проверок в минуту, 0 — без ограничения",
+		set: intSetter(func(c *Config) *int { return &c.Checker.GlobalRateLimit }),
+		get: func(c Config) string { return strconv.Itoa(c.Checker.GlobalRateLimit) }},
+	{key: "checker.default_timeout", env: "DOMAINPULSE_CHECK_TIMEOUT", flag: "check-timeout", usage: "таймаут проверок без timeout_ms",
+		set: durationSetter(func(c *Config) *time.Duration { return &c.Checker.DefaultTimeout }),
+		get: func(c Config) string { return c.Checker.DefaultTimeout.String() }},
+	{key: "notifications.timeout", env: "DOMAINPULSE_NOTIFICATION_TIMEOUT", flag: "notification-timeout", usage: "таймаут отправки уведомления", restart: true,
+		set: durationSetter(func(c *Config) *time.Duration { return &c.Notifications.Timeout }),
+		get: func(c Config) string { return c.Notifications.Timeout.String() }},
+	{key: "secrets.key", env: "DOMAINPULSE_SECRETS_KEY", usage: "ключ шифрования секретов уведомлений (base64, 32 байта)", restart: true,
+		set: func(c *Config, v string) error { c.Secrets.Key = strings.TrimSpace(v); return nil },
+		get: func(c Config) string { return c.Secrets.Key }},
+	{key: "secrets.previous_keys", env: "DOMAINPULSE_SECRETS_PREVIOUS_KEYS", usage: "прежние ключи шифрования через запятую, нужны для ротации", restart: true,
+		set: func(c *Config, v string) error { c.Secrets.PreviousKeys = splitList(v); return nil },
+		get: func(c Config) string { return strings.Join(c.Secrets.PreviousKeys, ",") }},
+}
+
+func intSetter(field func(c *Config) *int) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		n, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid integer %q", value)
+		}
+		*field(c) = n
+		return nil
+	}
+}
+
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func durationSetter(field func(c *Config) *time.Duration) func(c *Config, value string) error {
+	return func(c *Config, value string) error {
+		d, err := time.ParseDuration(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("invalid duration %q", value)
+		}
+		*field(c) = d
+		return nil
+	}
+}
+
+// Load собирает конфигурацию из args (обычно os.Args[1:]) и окружения и проверяет её.
+// Путь к файлу задаётся флагом -config или переменной DOMAINPULSE_CONFIG; без него файл не читается.
+func Load(args []string) (Config, error) {
+	fs := flag.NewFlagSet("domainpulse", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	configPath := fs.String("config", os.Getenv(ConfigEnv), "путь к файлу конфигурации (.yaml, .yml или .toml)")
+	type flagValue struct {
+		s     setting
+		value string
+	}
+	var flagValues []flagValue
+	for _, s := range settings {
+		s := s
+		if s.flag == "" {
+			continue
+		}
+		fs.Func(s.flag, fmt.Sprintf("%s (%s)", s.usage, s.env), func(value string) error {
+			flagValues = append(flagValues, flagValue{s: s, value: value})
+			return nil
+		})
+	}
+	if err := fs.Parse(args); err != nil {
+		return Config{}, err
+	}
+
+	cfg := Default()
+	if *configPath != "" {
+		if err := cfg.loadFile(*configPath); err != nil {
+			return Config{}, err
+		}
+	}
+	for _, s := range settings {
+		if value, ok := os.LookupEnv(s.env); ok {
+			if err := s.set(&cfg, value); err != nil {
+				return Config{}, fmt.Errorf("%s: %w", s.env, err)
+			}
+		}
+	}
+	for _, fv := range flagValues {
+		if err := fv.s.set(&cfg, fv.value); err != nil {
+			return Config{}, fmt.Errorf("-%s: %w", fv.s.flag, err)
+		}
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return Config{}, err
+	}
+	return cfg, nil
+}
+
+// Usage выводит описание флагов и соответствующих им переменных окружения.
+func Usage(w io.Writer) {
+	fmt.Fprintf(w, "Usage: domainpulse [flags]\n\n  -config string\n\tпуть к файлу конфигурации (.yaml, .yml или .toml) (%s)\n", ConfigEnv)
+	defaults := Default()
+	for _, s := range settings {
+		if s.flag == "" {
+			continue
+		}
+		fmt.Fprintf(w, "  -%s value\n\t%s (%s, %s; по умолчанию %s)\n", s.flag, s.usage, s.env, s.key, s.get(defaults))
+	}
+	fmt.Fprintf(w, "\nOnly in config file or environment:\n")
+	for _, s := range settings {
+		if s.flag == "" {
+			fmt.Fprintf(w, "  %s\n\t%s (%s)\n", s.env, s.usage, s.key)
+		}
+	}
+}
+
+func (c *Config) loadFile(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	var values map[string]string
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".yaml", ".yml":
+		values, err = parseYAML(data)
+	case ".toml":
+		values, err = parseTOML(data)
+	default:
+		return fmt.Errorf("unsupported config format %q: use .yaml, .yml or .toml", ext)
+	}
+	if err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+
+	for key, value := range values {
+		s, ok := settingByKey(key)
+		if !ok {
+			return fmt.Errorf("config %s: unknown key %q", path, key)
+		}
+		if err := s.set(c, value); err != nil {
+			return fmt.Errorf("config %s: %s: %w", path, key, err)
+		}
+	}
+	return nil
+}
+
+func settingByKey(key string) (setting, bool) {
+	for _, s := range settings {
+		if s.key == key {
+			return s, true
+		}
+	}
+	return setting{}, false
+}
+
+// parseYAML возвращает значения файла по ключам вида section.key.
+func parseYAML(data []byte) (map[string]string, error) {
+	var doc map[string]any
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	return flattenValues(doc)
+}
+
+// parseTOML возвращает значения файла по ключам вида section.key.
+func parseTOML(data []byte) (map[string]string, error) {
+	var doc map[string]any
+	if _, err := toml.Decode(string(data), &doc); err != nil {
+		return nil, err
+	}
+	return flattenValues(doc)
+}
+
+// flattenValues переводит разобранный документ в значения по ключам вида section.key.
+// Списки объединяются через запятую, как в переменных окружения.
+func flattenValues(doc map[string]any) (map[string]string, error) {
+	values := make(map[string]string)
+	var flatten func(prefix string, m map[string]any) error
+	flatten = func(prefix string, m map[string]any) error {
+		for k, v := range m {
+			key := prefix + k
+			switch v := v.(type) {
+			case map[string]any:
+				if err := flatten(key+".", v); err != nil {
+					return err
+				}
+			case []any:
+				items := make([]string, 0, len(v))
+				for _, item := range v {
+					switch item.(type) {
+					case map[string]any, []any:
+						return fmt.Errorf("%s: only lists of scalar values are supported", key)
+					}
+					items = append(items, fmt.Sprint(item))
+				}
+				values[key] = strings.Join(items, ",")
+			case []map[string]any:
+				return fmt.Errorf("%s: only lists of scalar values are supported", key)
+			case nil:
+			default:
+				values[key] = fmt.Sprint(v)
+			}
+		}
+		return nil
+	}
+	if err := flatten("", doc); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// Validate проверяет значения конфигурации.
+func (c Config) Validate() error {
+	if _, _, err := net.SplitHostPort(c.Server.Addr); err != nil {
+		return fmt.Errorf("server.addr: %w", err)
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		return errors.New("server.shutdown_timeout must be positive")
+	}
+	if c.Database.Path == "" {
+		return errors.New("database.path is required")
+	}
+	if c.Checker.Workers < 1 {
+		return errors.New("checker.workers must be at least 1")
+	}
+	if c.Checker.QueueSize < 1 {
+		return errors.New("checker.queue_size must be at least 1")
+	}
+	if c.Checker.GlobalRateLimit < 0 {
+		return errors.New("checker.global_rate_limit must be >= 0")
+	}
+	if c.Checker.DefaultTimeout <= 0 {
+		return errors.New("checker.default_timeout must be positive")
+	}
+	if c.Notifications.Timeout <= 0 {
+		return errors.New("notifications.timeout must be positive")
+	}
+	if _, err := secrets.NewKeyring(c.Secrets.Key, c.Secrets.PreviousKeys); err != nil {
+		return fmt.Errorf("secrets: %w", err)
+	}
+	return nil
+}
+
+// Reload возвращает конфигурацию next, в которой параметры, требующие перезапуска, оставлены
+// как в c, и ключи тех из них, что изменились в next.
+func (c Config) Reload(next Config) (Config, []string) {
+	var restart []string
+	for _, s := range settings {
+		if !s.restart || s.get(c) == s.get(next) {
+			continue
+		}
+		restart = append(restart, s.key)
+		_ = s.set(&next, s.get(c))
+	}
+	return next, restart
+}
@@ -0,0 +1,83 @@
+// Package events — шина событий внутри процесса: API публикует изменения проверок,
+// планировщик подписывается на них и сразу перепланирует затронутые проверки.
+package events
+
+import "sync"
+
+// CheckEventType — что произошло с проверкой.
+type CheckEventType string
+
+const (
+	CheckCreated  CheckEventType = "created"
+	CheckUpdated  CheckEventType = "updated"
+	CheckDeleted  CheckEventType = "deleted"
+	CheckEnabled  CheckEventType = "enabled"
+	CheckDisabled CheckEventType = "disabled"
+)
+
+// CheckEvent — событие изменения проверки. Подписчик сам читает актуальную конфигурацию из хранилища.
+type CheckEvent struct {
+	Type    CheckEventType
+	CheckID int
+}
+
+// Subscription — подписка на события. Канал Events не закрывается:
+// после Unsubscribe подписчик просто перестаёт его читать.
+type Subscription struct {
+	Events <-chan CheckEvent
+	ch     chan CheckEvent
+	done   chan struct{}
+}
+
+// Bus рассылает события всем подписчикам. Publish блокируется, пока каждый подписчик
+// не примет событие (или не отпишется), поэтому события не теряются.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+func NewBus() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe создаёт подписку с буфером buffer событий.
+func (b *Bus) Subscribe(buffer int) *Subscription {
+	ch := make(chan CheckEvent, buffer)
+	sub := &Subscription{Events: ch, ch: ch, done: make(chan struct{})}
+
+	b.mu.Lock()
+	b.subs[sub] = struct{}{}
+	b.mu.Unlock()
+	return sub
+}
+
+// Unsubscribe отменяет подписку и разблокирует ожидающие Publish.
+func (b *Bus) Unsubscribe(sub *Subscription) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.subs[sub]; !ok {
+		return
+	}
+	close(sub.done)
+	delete(b.subs, sub)
+}
+
+// Publish отправляет событие всем подписчикам. Вызов на nil шине ничего не делает.
+func (b *Bus) Publish(ev CheckEvent) {
+	if b == nil {
+		return
+	}
+	b.mu.RLock()
+	subs := make([]*Subscription, 0, len(b.subs))
+	for sub := range b.subs {
+		subs = append(subs, sub)
+	}
+	b.mu.RUnlock()
+
+	for _, sub := range subs {
+		select {
+		case sub.ch <- ev:
+		case <-sub.done:
+		}
+	}
+}
@@ -0,0 +1,87 @@
+// Package metrics — запись метрик в текстовом формате Prometheus (exposition format 0.0.4)
+// без внешних зависимостей.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ContentType — Content-Type ответа с метриками в текстовом формате.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+const (
+	TypeCounter = "counter"
+	TypeGauge   = "gauge"
+)
+
+type Label struct {
+	Name  string
+	Value string
+}
+
+// Writer пишет семейства метрик: сначала Family с описанием и типом, затем его значения через Sample.
+// Первая ошибка записи запоминается, последующие вызовы ничего не делают.
+type Writer struct {
+	w   io.Writer
+	err error
+}
+
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+func (w *Writer) Family(name, typ, help string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, helpEscaper.Replace(help), name, typ)
+}
+
+func (w *Writer) Sample(name string, value float64, labels ...Label) {
+	var b strings.Builder
+	b.WriteString(name)
+	if len(labels) > 0 {
+		b.WriteByte('{')
+		for i, l := range labels {
+			if i > 0 {
+				b.WriteByte(',')
+			}
+			b.WriteString(l.Name)
+			b.WriteString(`="`)
+			b.WriteString(labelEscaper.Replace(l.Value))
+			b.WriteByte('"')
+		}
+		b.WriteByte('}')
+	}
+	w.printf("%s %s\n", b.String(), formatValue(value))
+}
+
+// Err возвращает первую ошибку записи.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+func (w *Writer) printf(format string, args ...any) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.w, format, args...)
+}
+
+var (
+	helpEscaper  = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+	labelEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`, `"`, `\"`)
+)
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
@@ -7,6 +7,17 @@ type Domain struct {
 	Name string `json:"name" example:"example.com"`
 }
 
+// Уровни важности проверки (params.severity) — уровни PagerDuty Events v2.
+const (
+	SeverityCritical = "critical"
+	SeverityError    = "error"
+	SeverityWarning  = "warning"
+	SeverityInfo     = "info"
+
+	// DefaultSeverity — важность проверок без params.severity.
+	DefaultSeverity = SeverityError
+)
+
 // CheckParams — параметры проверки (путь, порт, схема, метод и т.д.)
 // @name CheckParams
 type CheckParams struct {
@@ -18,9 +29,51 @@ type CheckParams struct {
 	Method    string            `json:"method,omitempty" example:"GET"`
 	Body      string            `json:"body,omitempty" example:""`
 	Headers   map[string]string `json:"headers,omitempty" example:"{\"Authorization\": \"Bearer token\", \"X-Custom-Header\": \"value\"}"`
+
+	BodyContains    string `json:"body_contains,omitempty" example:"healthy"`
+	BodyNotContains string `json:"body_not_contains,omitempty" example:"Internal Server Error"`
+	BodyRegex       string `json:"body_regex,omitempty" example:"^OK$"`
+	JSONPath        string `json:"json_path,omitempty" example:"$.checks.db.status"`
+	JSONPathEquals  string `json:"json_path_equals,omitempty" example:"up"`
+	MaxBodyBytes    int64  `json:"max_body_bytes,omitempty" example:"1048576"`
+
+	ExpectedStatus  string `json:"expected_status,omitempty" example:"200,204,300-399"`
+	FollowRedirects *bool  `json:"follow_redirects,omitempty" example:"false"`
+	MaxRedirects    int    `json:"max_redirects,omitempty" example:"5"`
+
+	RecordType     string   `json:"record_type,omitempty" example:"A"`
+	QueryName      string   `json:"query_name,omitempty" example:"_sip._tcp.example.com"`
+	Resolver       string   `json:"resolver,omitempty" example:"8.8.8.8:53"`
+	ExpectedValues []string `json:"expected_values,omitempty" example:"93.184.216.34"`
+	MinRecords     int      `json:"min_records,omitempty" example:"1"`
+
+	VerifyCertificate  bool   `json:"verify_certificate,omitempty" example:"true"`
+	CABundle           string `json:"ca_bundle,omitempty" example:"-----BEGIN CERTIFICATE-----..."`
+	CertExpiryWarnDays int    `json:"cert_expiry_warn_days,omitempty" example:"30"`
+	CertExpiryFailDays int    `json:"cert_expiry_fail_days,omitempty" example:"7"`
+
+	PacketCount          int      `json:"packet_count,omitempty" example:"5"`
+	PacketIntervalMS     int      `json:"packet_interval_ms,omitempty" example:"200"`
+	MaxPacketLossPercent *float64 `json:"max_packet_loss_percent,omitempty" example:"20"`
+
+	Expect           string `json:"expect,omitempty" example:"+PONG"`
+	ExpectMode       string `json:"expect_mode,omitempty" example:"string"`
+	ReadTimeoutMS    int    `json:"read_timeout_ms,omitempty" example:"2000"`
+	MaxResponseBytes int    `json:"max_response_bytes,omitempty" example:"4096"`
+	PayloadEncoding  string `json:"payload_encoding,omitempty" example:"hex"`
+	FailOnNoResponse bool   `json:"fail_on_no_response,omitempty" example:"true"`
+
+	RenotifyIntervalMinutes int    `json:"renotify_interval_minutes,omitempty" example:"30"`
+	Severity                string `json:"severity,omitempty" example:"critical"`
+
+	Retries      int `json:"retries,omitempty" example:"2"`
+	RetryDelayMS int `json:"retry_delay_ms,omitempty" example:"2000"`
+
+	Cron         string `json:"cron,omitempty" example:"*/5 9-18 * * mon-fri"`
+	CronTimezone string `json:"cron_timezone,omitempty" example:"Europe/Moscow"`
 }
 
-// Check — проверка (http, icmp, tcp, udp, tls)
+// Check — проверка (http, icmp, tcp, udp, tls, dns)
 // @name Check
 type Check struct {
 	ID                 int         `json:"id" example:"1"`
@@ -37,14 +90,140 @@ type Check struct {
 // Result — результат одной проверки
 // @name Result
 type Result struct {
-	ID           int    `json:"id" example:"1"`
-	CheckID      int    `json:"check_id" example:"1"`
-	Status       string `json:"status" example:"success"`
-	StatusCode   int    `json:"status_code,omitempty" example:"200"`
-	DurationMS   int    `json:"duration_ms" example:"150"`
-	Outcome      string `json:"outcome,omitempty" example:"2xx"`
-	ErrorMessage string `json:"error_message,omitempty" example:""`
-	CreatedAt    string `json:"created_at" example:"2024-01-01T12:00:00Z"`
+	ID           int            `json:"id" example:"1"`
+	CheckID      int            `json:"check_id" example:"1"`
+	Status       string         `json:"status" example:"success"`
+	StatusCode   int            `json:"status_code,omitempty" example:"200"`
+	DurationMS   int            `json:"duration_ms" example:"150"`
+	Outcome      string         `json:"outcome,omitempty" example:"2xx"`
+	ErrorMessage string         `json:"error_message,omitempty" example:""`
+	Details      map[string]any `json:"details,omitempty"`
+	Timings      *PhaseTimings  `json:"timings,omitempty"`
+	Attempts     int            `json:"attempts" example:"1"`
+	Suppressed   bool           `json:"suppressed,omitempty" example:"false"`
+	CreatedAt    string         `json:"created_at" example:"2024-01-01T12:00:00Z"`
+}
+
+// PhaseTimings — длительность фаз HTTP запроса (мс): DNS, TCP connect, TLS handshake,
+// время до первого байта ответа и передача тела
+// @name PhaseTimings
+type PhaseTimings struct {
+	DNSMS      int `json:"dns_ms" example:"12"`
+	ConnectMS  int `json:"connect_ms" example:"25"`
+	TLSMS      int `json:"tls_ms" example:"40"`
+	TTFBMS     int `json:"ttfb_ms" example:"60"`
+	TransferMS int `json:"transfer_ms" example:"5"`
+}
+
+// PhaseAverages — средняя длительность фаз HTTP запроса (мс)
+// @name PhaseAverages
+type PhaseAverages struct {
+	DNS      float64 `json:"dns_ms" example:"12.5"`
+	Connect  float64 `json:"connect_ms" example:"25.1"`
+	TLS      float64 `json:"tls_ms" example:"40.2"`
+	TTFB     float64 `json:"ttfb_ms" example:"60.7"`
+	Transfer float64 `json:"transfer_ms" example:"5.3"`
+}
+
+// CheckDependencies — зависимости проверки: от каких проверок она зависит и какие зависят от неё.
+// Пока родительская проверка недоступна, сбои зависимых проверок сохраняются с флагом suppressed без уведомлений
+// @name CheckDependencies
+type CheckDependencies struct {
+	CheckID    int   `json:"check_id" example:"2"`
+	DependsOn  []int `json:"depends_on"`
+	Dependents []int `json:"dependents"`
+}
+
+// CheckDependenciesRequest — новый список родительских проверок (заменяет текущий)
+// @name CheckDependenciesRequest
+type CheckDependenciesRequest struct {
+	DependsOn []int `json:"depends_on"`
+}
+
+// MaintenanceWindow — окно обслуживания для проверки, домена или всех проверок.
+// Разовое окно задаётся starts_at и ends_at, повторяющееся — cron и duration_minutes
+// (starts_at и ends_at тогда ограничивают период действия расписания).
+// В режиме skip проверки не запускаются, в режиме mark результаты сохраняются со статусом maintenance.
+// Уведомления во время окна не отправляются, а результаты не учитываются в статистике
+// @name MaintenanceWindow
+type MaintenanceWindow struct {
+	ID              int    `json:"id" example:"1"`
+	Name            string `json:"name" example:"Обновление роутера"`
+	Scope           string `json:"scope" example:"domain"`
+	CheckID         int    `json:"check_id,omitempty" example:"0"`
+	DomainID        int    `json:"domain_id,omitempty" example:"1"`
+	StartsAt        string `json:"starts_at,omitempty" example:"2024-01-01T02:00:00Z"`
+	EndsAt          string `json:"ends_at,omitempty" example:"2024-01-01T04:00:00Z"`
+	Cron            string `json:"cron,omitempty" example:"0 3 * * sun"`
+	DurationMinutes int    `json:"duration_minutes,omitempty" example:"60"`
+	Timezone        string `json:"timezone,omitempty" example:"Europe/Moscow"`
+	Mode            string `json:"mode" example:"skip"`
+	Enabled         bool   `json:"enabled" example:"true"`
+	CreatedAt       string `json:"created_at,omitempty" example:"2024-01-01T12:00:00Z"`
+}
+
+// QueueStats — состояние очереди задач worker pool: глубина, число объединённых дубликатов
+// и задач, отброшенных из-за переполнения (всего и по проверкам)
+// @name QueueStats
+type QueueStats struct {
+	Depth          int            `json:"depth" example:"3"`
+	Capacity       int            `json:"capacity" example:"100"`
+	Workers        int            `json:"workers" example:"5"`
+	Enqueued       uint64         `json:"enqueued" example:"1200"`
+	Coalesced      uint64         `json:"coalesced" example:"4"`
+	Dropped        uint64         `json:"dropped" example:"0"`
+	DroppedByCheck map[int]uint64 `json:"dropped_by_check,omitempty"`
+}
+
+// CheckState — текущее состояние проверки (up/down) для уведомлений о смене состояния
+// @name CheckState
+type CheckState struct {
+	CheckID             int    `json:"check_id" example:"1"`
+	State               string `json:"state" example:"down"`
+	Since               string `json:"since" example:"2024-01-01T12:00:00Z"`
+	LastNotifiedAt      string `json:"last_notified_at,omitempty" example:"2024-01-01T12:00:00Z"`
+	ConsecutiveFailures int    `json:"consecutive_failures" example:"3"`
+}
+
+// Incident — инцидент: период недоступности проверки от первого сбоя до восстановления
+// @name Incident
+type Incident struct {
+	ID              int      `json:"id" example:"1"`
+	CheckID         int      `json:"check_id" example:"1"`
+	Status          string   `json:"status" example:"acknowledged"`
+	Cause           string   `json:"cause,omitempty" example:"connection refused"`
+	StartedAt       string   `json:"started_at" example:"2024-01-01T12:00:00Z"`
+	AcknowledgedAt  string   `json:"acknowledged_at,omitempty" example:"2024-01-01T12:05:00Z"`
+	AcknowledgedBy  string   `json:"acknowledged_by,omitempty" example:"oncall"`
+	AckNote         string   `json:"ack_note,omitempty" example:"investigating database"`
+	ResolvedAt      string   `json:"resolved_at,omitempty" example:"2024-01-01T12:30:00Z"`
+	ResolutionNote  string   `json:"resolution_note,omitempty" example:"auto-resolved: check recovered"`
+	DurationSeconds int64    `json:"duration_seconds" example:"1800"`
+	ResultCount     int      `json:"result_count" example:"180"`
+	Results         []Result `json:"results,omitempty"`
+}
+
+// IncidentsResponse — ответ со списком инцидентов и пагинацией
+// @name IncidentsResponse
+type IncidentsResponse struct {
+	Incidents  []Incident `json:"incidents"`
+	Total      int        `json:"total"`
+	Page       int        `json:"page"`
+	PageSize   int        `json:"page_size"`
+	TotalPages int        `json:"total_pages"`
+}
+
+// IncidentAckRequest — подтверждение инцидента
+// @name IncidentAckRequest
+type IncidentAckRequest struct {
+	AcknowledgedBy string `json:"acknowledged_by,omitempty" example:"oncall"`
+	Note           string `json:"note,omitempty" example:"investigating database"`
+}
+
+// IncidentResolveRequest — ручное закрытие инцидента
+// @name IncidentResolveRequest
+type IncidentResolveRequest struct {
+	Note string `json:"note,omitempty" example:"fixed by restarting the service"`
 }
 
 // ResultsResponse — ответ со списком результатов и пагинацией
@@ -71,9 +250,10 @@ type LatencyStats struct {
 // StatsResponse — ответ со статистикой проверки
 // @name StatsResponse
 type StatsResponse struct {
-	TotalResults       int            `json:"total_results" example:"1000"`
-	StatusDistribution map[string]int `json:"status_distribution"`
-	LatencyStats       LatencyStats   `json:"latency_stats"`
+	TotalResults       int                     `json:"total_results" example:"1000"`
+	StatusDistribution map[string]int          `json:"status_distribution"`
+	LatencyStats       LatencyStats            `json:"latency_stats"`
+	PhaseStats         map[string]LatencyStats `json:"phase_stats,omitempty"`
 }
 
 // TimeIntervalData — агрегированные данные по одному тайм-интервалу
@@ -87,6 +267,7 @@ type TimeIntervalData struct {
 	MinLatency         int            `json:"min_latency" example:"50"`
 	MaxLatency         int            `json:"max_latency" example:"500"`
 	StatusDistribution map[string]int `json:"status_distribution"`
+	AvgPhases          *PhaseAverages `json:"avg_phases,omitempty"`
 }
 
 // TimeIntervalResponse — ответ с данными по интервалам для графиков
@@ -100,17 +281,76 @@ type TimeIntervalResponse struct {
 	TotalPages int                `json:"total_pages,omitempty"`
 }
 
-// NotificationSettings — настройки уведомлений (Telegram, Slack)
+// User — пользователь API. Role: viewer (чтение), editor (изменение доменов, проверок, окон обслуживания
+// и инцидентов) или admin (всё, включая уведомления, пользователей и ключи)
+// @name User
+type User struct {
+	ID        int    `json:"id" example:"1"`
+	Name      string `json:"name" example:"oncall"`
+	Role      string `json:"role" example:"editor" enums:"viewer,editor,admin"`
+	CreatedAt string `json:"created_at,omitempty" example:"2024-01-01T12:00:00Z"`
+}
+
+// UserRequest — создание пользователя или изменение его имени и роли
+// @name UserRequest
+type UserRequest struct {
+	Name string `json:"name" example:"oncall"`
+	Role string `json:"role" example:"editor" enums:"viewer,editor,admin"`
+}
+
+// APIKey — ключ API пользователя. Сам ключ не хранится, известно только его начало (prefix)
+// @name APIKey
+type APIKey struct {
+	ID         int    `json:"id" example:"1"`
+	UserID     int    `json:"user_id" example:"1"`
+	Name       string `json:"name" example:"grafana"`
+	Prefix     string `json:"prefix" example:"dp_Xk3vQ9aB"`
+	CreatedAt  string `json:"created_at,omitempty" example:"2024-01-01T12:00:00Z"`
+	LastUsedAt string `json:"last_used_at,omitempty" example:"2024-01-02T08:30:00Z"`
+}
+
+// APIKeyRequest — создание ключа API
+// @name APIKeyRequest
+type APIKeyRequest struct {
+	Name string `json:"name" example:"grafana"`
+}
+
+// CreatedAPIKey — созданный ключ API. Key возвращается только при создании
+// @name CreatedAPIKey
+type CreatedAPIKey struct {
+	APIKey
+	Key string `json:"key" example:"dp_Xk3vQ9aB..."`
+}
+
+// NotificationSettings — настройки уведомлений (Telegram, Slack, вебхук, email, Discord, Teams, Mattermost).
+// Для вебхука webhook_url — адрес получателя, secret — ключ подписи HMAC-SHA256,
+// headers — дополнительные заголовки запроса (например, для авторизации).
+// Для email smtp_security — starttls (по умолчанию), tls или none; smtp_port по умолчанию
+// 587, 465 или 25 соответственно. Discord, Teams и Mattermost используют webhook_url входящего вебхука;
+// username — имя отправителя (Discord, Mattermost), channel — канал Mattermost.
+// Для PagerDuty token — ключ интеграции (routing key), для Opsgenie — ключ API; api_url заменяет адрес API по умолчанию
 // @name NotificationSettings
 type NotificationSettings struct {
-	ID                    int    `json:"id" example:"1"`
-	Type                  string `json:"type" example:"telegram"`
-	Enabled               bool   `json:"enabled" example:"true"`
-	Token                 string `json:"token,omitempty" example:"123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11"`
-	ChatID                string `json:"chat_id,omitempty" example:"-1001234567890"`
-	WebhookURL            string `json:"webhook_url,omitempty" example:"https://hooks.slack.com/services/..."`
-	NotifyOnFailure       bool   `json:"notify_on_failure" example:"true"`
-	NotifyOnSuccess       bool   `json:"notify_on_success" example:"false"`
-	NotifyOnSlowResponse  bool   `json:"notify_on_slow_response" example:"true"`
-	SlowResponseThreshold int    `json:"slow_response_threshold_ms" example:"1000"`
+	ID                    int               `json:"id" example:"1"`
+	Type                  string            `json:"type" example:"telegram"`
+	Enabled               bool              `json:"enabled" example:"true"`
+	Token                 string            `json:"token,omitempty" example:"123456:ABC-DEF1234ghIkl-zyx57W2v1u123ew11"`
+	ChatID                string            `json:"chat_id,omitempty" example:"-1001234567890"`
+	WebhookURL            string            `json:"webhook_url,omitempty" example:"https://hooks.slack.com/services/..."`
+	Secret                string            `json:"secret,omitempty" example:"whsec_3f9a..."`
+	Headers               map[string]string `json:"headers,omitempty"`
+	SMTPHost              string            `json:"smtp_host,omitempty" example:"smtp.example.com"`
+	SMTPPort              int               `json:"smtp_port,omitempty" example:"587"`
+	SMTPSecurity          string            `json:"smtp_security,omitempty" example:"starttls"`
+	SMTPUsername          string            `json:"smtp_username,omitempty" example:"alerts@example.com"`
+	SMTPPassword          string            `json:"smtp_password,omitempty" example:"app-password"`
+	EmailFrom             string            `json:"email_from,omitempty" example:"DomainPulse <alerts@example.com>"`
+	EmailTo               []string          `json:"email_to,omitempty" example:"ops@example.com"`
+	Username              string            `json:"username,omitempty" example:"DomainPulse"`
+	Channel               string            `json:"channel,omitempty" example:"town-square"`
+	APIURL                string            `json:"api_url,omitempty" example:"https://api.eu.opsgenie.com"`
+	NotifyOnFailure       bool              `json:"notify_on_failure" example:"true"`
+	NotifyOnSuccess       bool              `json:"notify_on_success" example:"false"`
+	NotifyOnSlowResponse  bool              `json:"notify_on_slow_response" example:"true"`
+	SlowResponseThreshold int               `json:"slow_response_threshold_ms" example:"1000"`
 }
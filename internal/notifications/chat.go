@@ -0,0 +1,223 @@
+package notifications
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// Цвета уведомлений: восстановление, сбой и медленный ответ.
+const (
+	colorGood    = 0x2ECC71
+	colorDanger  = 0xE74C3C
+	colorWarning = 0xF1C40F
+)
+
+// statusColor выбирает цвет уведомления по тому же принципу, что и statusEmoji.
+func statusColor(msg NotificationMessage) int {
+	switch statusEmoji(msg) {
+	case "❌":
+		return colorDanger
+	case "⚠️":
+		return colorWarning
+	default:
+		return colorGood
+	}
+}
+
+// chatTitle — заголовок уведомления в чатах, например "❌ Domain Check: DOWN".
+func chatTitle(msg NotificationMessage) string {
+	title := statusEmoji(msg) + " Domain Check"
+	if event := eventTitle(msg); event != "" {
+		title += ": " + event
+	}
+	return title
+}
+
+// truncate обрезает s до limit символов, чтобы длинные ошибки не превышали ограничений полей мессенджеров.
+func truncate(s string, limit int) string {
+	runes := []rune(s)
+	if len(runes) <= limit {
+		return s
+	}
+	return string(runes[:limit-1]) + "…"
+}
+
+// ValidateChat проверяет настройки Discord, Teams и Mattermost: адрес входящего вебхука
+// и необязательные имя отправителя (Discord, Mattermost) и канал (Mattermost).
+func ValidateChat(settings models.NotificationSettings) error {
+	// Mattermost часто разворачивают внутри сети без TLS, поэтому для него допускается http.
+	if err := validateWebhookURL(settings.WebhookURL, settings.Type != TypeMattermost); err != nil {
+		return err
+	}
+	if settings.Username != "" && settings.Type == TypeTeams {
+		return errors.New("username is not supported for teams")
+	}
+	if len([]rune(settings.Username)) > 80 {
+		return errors.New("username must be at most 80 characters")
+	}
+	if settings.Channel != "" && settings.Type != TypeMattermost {
+		return errors.New("channel is supported only for mattermost")
+	}
+	return nil
+}
+
+// postJSON отправляет payload на endpoint канала channel; успешным считается любой ответ 2xx
+// (Discord отвечает 204, Teams, PagerDuty и Opsgenie — 202). Начало тела ответа с ошибкой
+// попадает в текст ошибки: API обычно объясняют в нём причину отказа.
+func (ns *NotificationSender) postJSON(channel, endpoint string, header http.Header, payload any) error {
+	if endpoint == "" {
+		return fmt.Errorf("%s webhook_url is required", channel)
+	}
+
+	jsonData, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("marshal %s payload: %w", channel, err)
+	}
+
+	req, err := http.NewRequest("POST", endpoint, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("create %s request: %w", channel, err)
+	}
+	for name, values := range header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := ns.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send %s message: %w", channel, err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if text := strings.TrimSpace(string(body)); text != "" {
+			return fmt.Errorf("%s returned status %d: %s", channel, resp.StatusCode, text)
+		}
+		return fmt.Errorf("%s returned status %d", channel, resp.StatusCode)
+	}
+	return nil
+}
+
+type discordField struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Inline bool   `json:"inline"`
+}
+
+type discordEmbed struct {
+	Title     string         `json:"title"`
+	Color     int            `json:"color"`
+	Fields    []discordField `json:"fields"`
+	Timestamp string         `json:"timestamp,omitempty"`
+}
+
+type discordMessage struct {
+	Username string         `json:"username,omitempty"`
+	Embeds   []discordEmbed `json:"embeds"`
+}
+
+// formatDiscordMessage строит embed, окрашенный по статусу. Ошибка выводится отдельной строкой
+// и обрезается до ограничения Discord на значение поля (1024 символа).
+func formatDiscordMessage(settings models.NotificationSettings, msg NotificationMessage) discordMessage {
+	embed := discordEmbed{Title: chatTitle(msg), Color: statusColor(msg), Timestamp: msg.CreatedAt}
+	for _, f := range messageFields(msg) {
+		if f[0] == "Time" {
+			continue
+		}
+		embed.Fields = append(embed.Fields, discordField{Name: f[0], Value: truncate(f[1], 1024), Inline: f[0] != "Error"})
+	}
+	return discordMessage{Username: settings.Username, Embeds: []discordEmbed{embed}}
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string           `json:"$schema"`
+	Type    string           `json:"type"`
+	Version string           `json:"version"`
+	Body    []map[string]any `json:"body"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+// formatTeamsMessage строит Adaptive Card для входящего вебхука Teams (Workflows):
+// заголовок окрашен по статусу, поля выводятся списком фактов.
+func formatTeamsMessage(msg NotificationMessage) teamsMessage {
+	color := "Good"
+	switch statusColor(msg) {
+	case colorDanger:
+		color = "Attention"
+	case colorWarning:
+		color = "Warning"
+	}
+
+	facts := make([]teamsFact, 0, 7)
+	for _, f := range messageFields(msg) {
+		facts = append(facts, teamsFact{Title: f[0], Value: f[1]})
+	}
+
+	card := teamsCard{
+		Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+		Type:    "AdaptiveCard",
+		Version: "1.4",
+		Body: []map[string]any{
+			{"type": "TextBlock", "text": chatTitle(msg), "weight": "Bolder", "size": "Medium", "color": color, "wrap": true},
+			{"type": "FactSet", "facts": facts},
+		},
+	}
+	return teamsMessage{
+		Type:        "message",
+		Attachments: []teamsAttachment{{ContentType: "application/vnd.microsoft.card.adaptive", Content: card}},
+	}
+}
+
+type mattermostField struct {
+	Short bool   `json:"short"`
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+type mattermostAttachment struct {
+	Fallback string            `json:"fallback"`
+	Color    string            `json:"color"`
+	Title    string            `json:"title"`
+	Fields   []mattermostField `json:"fields"`
+}
+
+type mattermostMessage struct {
+	Username    string                 `json:"username,omitempty"`
+	Channel     string                 `json:"channel,omitempty"`
+	Attachments []mattermostAttachment `json:"attachments"`
+}
+
+// formatMattermostMessage строит сообщение с вложением: цветная полоса по статусу и поля в две колонки.
+func formatMattermostMessage(settings models.NotificationSettings, msg NotificationMessage) mattermostMessage {
+	attachment := mattermostAttachment{
+		Fallback: fmt.Sprintf("%s: %s (%s) %s", chatTitle(msg), msg.DomainName, msg.CheckType, msg.Status),
+		Color:    fmt.Sprintf("#%06X", statusColor(msg)),
+		Title:    chatTitle(msg),
+	}
+	for _, f := range messageFields(msg) {
+		attachment.Fields = append(attachment.Fields, mattermostField{Short: f[0] != "Error", Title: f[0], Value: f[1]})
+	}
+	return mattermostMessage{Username: settings.Username, Channel: settings.Channel, Attachments: []mattermostAttachment{attachment}}
+}
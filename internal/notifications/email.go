@@ -0,0 +1,275 @@
+package notifications
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// Режимы шифрования SMTP соединения: STARTTLS после подключения, TLS с самого начала (SMTPS) или без шифрования.
+const (
+	SMTPSecurityStartTLS = "starttls"
+	SMTPSecurityTLS      = "tls"
+	SMTPSecurityNone     = "none"
+)
+
+// SMTPDialFunc открывает соединение с SMTP сервером. Подменяется, например, чтобы отправлять
+// письма на SMTP сервер внутри процесса.
+type SMTPDialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// SetSMTPDialer задаёт функцию подключения к SMTP серверу и базовую конфигурацию TLS
+// (например, с собственным корневым сертификатом). nil оставляет значение по умолчанию.
+func (ns *NotificationSender) SetSMTPDialer(dial SMTPDialFunc, tlsConfig *tls.Config) {
+	ns.dialSMTP = dial
+	ns.smtpTLS = tlsConfig
+}
+
+// smtpPort возвращает порт SMTP сервера: заданный или стандартный для режима шифрования.
+func smtpPort(settings models.NotificationSettings) int {
+	if settings.SMTPPort > 0 {
+		return settings.SMTPPort
+	}
+	switch settings.SMTPSecurity {
+	case SMTPSecurityTLS:
+		return 465
+	case SMTPSecurityNone:
+		return 25
+	default:
+		return 587
+	}
+}
+
+// ValidateEmail проверяет настройки SMTP сервера, отправителя и получателей.
+func ValidateEmail(settings models.NotificationSettings) error {
+	if settings.SMTPHost == "" {
+		return errors.New("smtp_host is required for email")
+	}
+	if settings.SMTPPort < 0 || settings.SMTPPort > 65535 {
+		return errors.New("smtp_port must be between 1 and 65535")
+	}
+	switch settings.SMTPSecurity {
+	case "", SMTPSecurityStartTLS, SMTPSecurityTLS, SMTPSecurityNone:
+	default:
+		return errors.New("smtp_security must be 'starttls', 'tls' or 'none'")
+	}
+	if settings.SMTPPassword != "" && settings.SMTPUsername == "" {
+		return errors.New("smtp_username is required with smtp_password")
+	}
+	if settings.SMTPSecurity == SMTPSecurityNone && (settings.SMTPUsername != "" || settings.SMTPPassword != "") &&
+		!isLoopbackHost(settings.SMTPHost) {
+		return errors.New("smtp_username and smtp_password require smtp_security 'starttls' or 'tls' unless smtp_host is a loopback address")
+	}
+	if _, err := mail.ParseAddress(settings.EmailFrom); err != nil {
+		return errors.New("email_from must be a valid email address")
+	}
+	if len(settings.EmailTo) == 0 {
+		return errors.New("email_to must contain at least one recipient")
+	}
+	for _, to := range settings.EmailTo {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("invalid recipient %q", to)
+		}
+	}
+	return nil
+}
+
+// isLoopbackHost сообщает, указывает ли host на локальную машину: только туда учётные данные
+// можно передавать без шифрования. Набор адресов совпадает с тем, что допускает smtp.PlainAuth.
+func isLoopbackHost(host string) bool {
+	return host == "localhost" || host == "127.0.0.1" || host == "::1"
+}
+
+func (ns *NotificationSender) sendEmail(settings models.NotificationSettings, msg NotificationMessage) error {
+	if err := ValidateEmail(settings); err != nil {
+		return err
+	}
+	from, _ := mail.ParseAddress(settings.EmailFrom)
+	recipients := make([]*mail.Address, 0, len(settings.EmailTo))
+	for _, to := range settings.EmailTo {
+		addr, _ := mail.ParseAddress(to)
+		recipients = append(recipients, addr)
+	}
+
+	body, err := buildEmail(from, recipients, msg, time.Now())
+	if err != nil {
+		return fmt.Errorf("build email: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), ns.client.Timeout)
+	defer cancel()
+
+	client, err := ns.smtpClient(ctx, settings)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if settings.SMTPUsername != "" {
+		auth := smtp.PlainAuth("", settings.SMTPUsername, settings.SMTPPassword, settings.SMTPHost)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("smtp auth: %w", err)
+		}
+	}
+	if err := client.Mail(from.Address); err != nil {
+		return fmt.Errorf("smtp mail from: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt.Address); err != nil {
+			return fmt.Errorf("smtp rcpt to %s: %w", rcpt.Address, err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("smtp data: %w", err)
+	}
+	if _, err := w.Write(body); err != nil {
+		return fmt.Errorf("write email: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("send email: %w", err)
+	}
+	// Письмо принято сервером; ошибка при завершении сеанса на доставку уже не влияет.
+	_ = client.Quit()
+	return nil
+}
+
+// smtpClient подключается к SMTP серверу и, в зависимости от режима, включает TLS.
+// Соединение ограничено по времени таймаутом отправки уведомлений.
+func (ns *NotificationSender) smtpClient(ctx context.Context, settings models.NotificationSettings) (*smtp.Client, error) {
+	dial := ns.dialSMTP
+	if dial == nil {
+		dial = (&net.Dialer{}).DialContext
+	}
+	addr := net.JoinHostPort(settings.SMTPHost, strconv.Itoa(smtpPort(settings)))
+	conn, err := dial(ctx, "tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("connect to smtp server: %w", err)
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+
+	tlsConfig := &tls.Config{}
+	if ns.smtpTLS != nil {
+		tlsConfig = ns.smtpTLS.Clone()
+	}
+	tlsConfig.ServerName = settings.SMTPHost
+
+	if settings.SMTPSecurity == SMTPSecurityTLS {
+		conn = tls.Client(conn, tlsConfig)
+	}
+	client, err := smtp.NewClient(conn, settings.SMTPHost)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("smtp handshake: %w", err)
+	}
+	if settings.SMTPSecurity == "" || settings.SMTPSecurity == SMTPSecurityStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			client.Close()
+			return nil, errors.New("smtp server does not support STARTTLS")
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			client.Close()
+			return nil, fmt.Errorf("smtp starttls: %w", err)
+		}
+	}
+	return client, nil
+}
+
+// emailSubject — тема письма, например "[DomainPulse] DOWN: example.com (http)".
+func emailSubject(msg NotificationMessage) string {
+	title := eventTitle(msg)
+	if title == "" {
+		title = strings.ToUpper(strings.ReplaceAll(msg.Status, "_", " "))
+	}
+	return fmt.Sprintf("[DomainPulse] %s: %s (%s)", title, msg.DomainName, msg.CheckType)
+}
+
+// formatPlainText — текст уведомления без разметки: тело письма и описание алерта Opsgenie.
+func formatPlainText(msg NotificationMessage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s %s\n\n", statusEmoji(msg), emailSubject(msg))
+	for _, f := range messageFields(msg) {
+		fmt.Fprintf(&b, "%s: %s\n", f[0], f[1])
+	}
+	return b.String()
+}
+
+func formatEmailHTML(msg NotificationMessage) string {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html><body style=\"font-family: sans-serif\">\n")
+	fmt.Fprintf(&b, "<h2>%s %s</h2>\n<table cellpadding=\"4\">\n", statusEmoji(msg), html.EscapeString(emailSubject(msg)))
+	for _, f := range messageFields(msg) {
+		fmt.Fprintf(&b, "<tr><th align=\"left\">%s</th><td>%s</td></tr>\n", f[0], html.EscapeString(f[1]))
+	}
+	b.WriteString("</table>\n</body></html>\n")
+	return b.String()
+}
+
+// buildEmail собирает письмо multipart/alternative с текстовой и HTML версиями.
+func buildEmail(from *mail.Address, to []*mail.Address, msg NotificationMessage, now time.Time) ([]byte, error) {
+	var body bytes.Buffer
+	parts := multipart.NewWriter(&body)
+	for _, part := range []struct{ contentType, content string }{
+		{"text/plain; charset=UTF-8", formatPlainText(msg)},
+		{"text/html; charset=UTF-8", formatEmailHTML(msg)},
+	} {
+		w, err := parts.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.content)); err != nil {
+			return nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return nil, err
+		}
+	}
+	if err := parts.Close(); err != nil {
+		return nil, err
+	}
+
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return nil, err
+	}
+	_, domain, _ := strings.Cut(from.Address, "@")
+
+	var m bytes.Buffer
+	header := func(name, value string) { fmt.Fprintf(&m, "%s: %s\r\n", name, value) }
+	header("From", from.String())
+	recipients := make([]string, len(to))
+	for i, addr := range to {
+		recipients[i] = addr.String()
+	}
+	header("To", strings.Join(recipients, ", "))
+	header("Subject", mime.QEncoding.Encode("utf-8", emailSubject(msg)))
+	header("Date", now.Format(time.RFC1123Z))
+	header("Message-ID", fmt.Sprintf("<%s@%s>", hex.EncodeToString(id), domain))
+	header("MIME-Version", "1.0")
+	header("Content-Type", "multipart/alternative; boundary="+parts.Boundary())
+	m.WriteString("\r\n")
+	m.Write(body.Bytes())
+	return m.Bytes(), nil
+}
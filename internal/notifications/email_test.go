@@ -0,0 +1,347 @@
+package notifications
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"io"
+	"math/big"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"net/textproto"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+const testSMTPHost = "mail.example.com"
+
+// smtpSession — то, что SMTP сервер-заглушка получил от клиента.
+type smtpSession struct {
+	tlsOnAuth bool
+	auth      string
+	from      string
+	rcpt      []string
+	data      []byte
+}
+
+// smtpStub — минимальный SMTP сервер на net.Listener: поддерживает EHLO, STARTTLS, AUTH PLAIN,
+// MAIL, RCPT, DATA и QUIT и обслуживает одно соединение.
+type smtpStub struct {
+	ln       net.Listener
+	tls      *tls.Config
+	startTLS bool
+	sessions chan smtpSession
+}
+
+// newSMTPStub запускает сервер. implicitTLS включает TLS с самого начала соединения,
+// startTLS — поддержку команды STARTTLS.
+func newSMTPStub(t *testing.T, implicitTLS, startTLS bool) (*smtpStub, *x509.CertPool) {
+	t.Helper()
+	cert, roots := testCertificate(t)
+	serverTLS := &tls.Config{Certificates: []tls.Certificate{cert}}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	if implicitTLS {
+		ln = tls.NewListener(ln, serverTLS)
+	}
+	stub := &smtpStub{ln: ln, tls: serverTLS, startTLS: startTLS, sessions: make(chan smtpSession, 1)}
+	t.Cleanup(func() { ln.Close() })
+
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		stub.sessions <- stub.serve(conn)
+	}()
+	return stub, roots
+}
+
+func (s *smtpStub) serve(conn net.Conn) smtpSession {
+	defer conn.Close()
+	_ = conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+	var session smtpSession
+	_, secure := conn.(*tls.Conn)
+	tp := textproto.NewConn(conn)
+	_ = tp.PrintfLine("220 %s ESMTP", testSMTPHost)
+	for {
+		line, err := tp.ReadLine()
+		if err != nil {
+			return session
+		}
+		cmd, arg, _ := strings.Cut(line, " ")
+		switch strings.ToUpper(cmd) {
+		case "EHLO", "HELO":
+			ext := []string{testSMTPHost, "8BITMIME", "AUTH PLAIN"}
+			if s.startTLS && !secure {
+				ext = append(ext, "STARTTLS")
+			}
+			for i, e := range ext {
+				sep := "-"
+				if i == len(ext)-1 {
+					sep = " "
+				}
+				_ = tp.PrintfLine("250%s%s", sep, e)
+			}
+		case "STARTTLS":
+			_ = tp.PrintfLine("220 ready to start TLS")
+			tlsConn := tls.Server(conn, s.tls)
+			if err := tlsConn.Handshake(); err != nil {
+				return session
+			}
+			conn, secure = tlsConn, true
+			tp = textproto.NewConn(conn)
+		case "AUTH":
+			mech, initial, _ := strings.Cut(arg, " ")
+			decoded, err := base64.StdEncoding.DecodeString(initial)
+			if !strings.EqualFold(mech, "PLAIN") || err != nil {
+				_ = tp.PrintfLine("504 unsupported authentication")
+				continue
+			}
+			session.tlsOnAuth, session.auth = secure, string(decoded)
+			_ = tp.PrintfLine("235 authenticated")
+		case "MAIL":
+			session.from = angleAddr(arg)
+			_ = tp.PrintfLine("250 ok")
+		case "RCPT":
+			session.rcpt = append(session.rcpt, angleAddr(arg))
+			_ = tp.PrintfLine("250 ok")
+		case "DATA":
+			_ = tp.PrintfLine("354 end data with <CR><LF>.<CR><LF>")
+			session.data, err = tp.ReadDotBytes()
+			if err != nil {
+				return session
+			}
+			_ = tp.PrintfLine("250 queued")
+		case "QUIT":
+			_ = tp.PrintfLine("221 bye")
+			return session
+		default:
+			_ = tp.PrintfLine("502 command not implemented")
+		}
+	}
+}
+
+func (s *smtpStub) session(t *testing.T) smtpSession {
+	t.Helper()
+	select {
+	case session := <-s.sessions:
+		return session
+	case <-time.After(5 * time.Second):
+		t.Fatal("smtp session did not finish")
+		return smtpSession{}
+	}
+}
+
+// angleAddr извлекает адрес из аргумента MAIL FROM:<...> или RCPT TO:<...>.
+func angleAddr(arg string) string {
+	_, rest, _ := strings.Cut(arg, "<")
+	addr, _, _ := strings.Cut(rest, ">")
+	return addr
+}
+
+// testCertificate создаёт самоподписанный сертификат для testSMTPHost и пул с ним в качестве корневого.
+func testCertificate(t *testing.T) (tls.Certificate, *x509.CertPool) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		DNSNames:              []string{testSMTPHost},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	leaf, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	roots := x509.NewCertPool()
+	roots.AddCert(leaf)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key, Leaf: leaf}, roots
+}
+
+// stubSender возвращает отправителя, который подключается к stub вместо настоящего сервера
+// и запоминает адрес, по которому подключался.
+func stubSender(stub *smtpStub, roots *x509.CertPool, dialed *string) *NotificationSender {
+	ns := NewNotificationSender(5 * time.Second)
+	ns.SetSMTPDialer(func(ctx context.Context, network, addr string) (net.Conn, error) {
+		*dialed = addr
+		var d net.Dialer
+		return d.DialContext(ctx, network, stub.ln.Addr().String())
+	}, &tls.Config{RootCAs: roots})
+	return ns
+}
+
+func testEmailSettings(security string) models.NotificationSettings {
+	return models.NotificationSettings{
+		Type:         "email",
+		Enabled:      true,
+		SMTPHost:     testSMTPHost,
+		SMTPSecurity: security,
+		SMTPUsername: "monitor",
+		SMTPPassword: "s3cret",
+		EmailFrom:    "DomainPulse <pulse@example.com>",
+		EmailTo:      []string{"ops@example.com", "Dev Team <dev@example.com>"},
+	}
+}
+
+func testEmailMessage() NotificationMessage {
+	return NotificationMessage{
+		CheckID:      7,
+		DomainName:   "example.com",
+		CheckType:    "http",
+		Status:       "failure",
+		Event:        "down",
+		ErrorMessage: "unexpected status <503>",
+		DurationMS:   120,
+		CreatedAt:    "2024-01-01T10:00:00Z",
+	}
+}
+
+// assertEmailSession проверяет аутентификацию, отправителя, получателей и обе части письма.
+func assertEmailSession(t *testing.T, session smtpSession) {
+	t.Helper()
+	if !session.tlsOnAuth {
+		t.Error("AUTH was sent over an unencrypted connection")
+	}
+	if want := "\x00monitor\x00s3cret"; session.auth != want {
+		t.Errorf("AUTH PLAIN = %q, want %q", session.auth, want)
+	}
+	if session.from != "pulse@example.com" {
+		t.Errorf("MAIL FROM = %q, want pulse@example.com", session.from)
+	}
+	if got := strings.Join(session.rcpt, ","); got != "ops@example.com,dev@example.com" {
+		t.Errorf("RCPT TO = %q, want ops@example.com,dev@example.com", got)
+	}
+
+	m, err := mail.ReadMessage(strings.NewReader(string(session.data)))
+	if err != nil {
+		t.Fatalf("parse message: %v", err)
+	}
+	if to := m.Header.Get("To"); !strings.Contains(to, "ops@example.com") || !strings.Contains(to, "<dev@example.com>") {
+		t.Errorf("To = %q, want both recipients", to)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(m.Header.Get("Subject"))
+	if err != nil || subject != "[DomainPulse] DOWN: example.com (http)" {
+		t.Errorf("Subject = %q (%v)", subject, err)
+	}
+	mediaType, params, err := mime.ParseMediaType(m.Header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", m.Header.Get("Content-Type"), err)
+	}
+
+	parts := map[string]string{}
+	mr := multipart.NewReader(m.Body, params["boundary"])
+	for {
+		part, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		content, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		partType, _, _ := mime.ParseMediaType(part.Header.Get("Content-Type"))
+		parts[partType] = string(content)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("got parts %v, want text/plain and text/html", parts)
+	}
+	if text := parts["text/plain"]; !strings.Contains(text, "Domain: example.com") || !strings.Contains(text, "Error: unexpected status <503>") {
+		t.Errorf("text/plain part = %q", text)
+	}
+	if body := parts["text/html"]; !strings.Contains(body, "<td>example.com</td>") || !strings.Contains(body, "unexpected status &lt;503&gt;") {
+		t.Errorf("text/html part = %q", body)
+	}
+}
+
+func TestSendEmailStartTLS(t *testing.T) {
+	stub, roots := newSMTPStub(t, false, true)
+	var dialed string
+	ns := stubSender(stub, roots, &dialed)
+
+	if err := ns.SendNotification(testEmailSettings(""), testEmailMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if dialed != testSMTPHost+":587" {
+		t.Errorf("dialed %q, want default STARTTLS port 587", dialed)
+	}
+	assertEmailSession(t, stub.session(t))
+}
+
+func TestSendEmailImplicitTLS(t *testing.T) {
+	stub, roots := newSMTPStub(t, true, false)
+	var dialed string
+	ns := stubSender(stub, roots, &dialed)
+
+	if err := ns.SendNotification(testEmailSettings(SMTPSecurityTLS), testEmailMessage()); err != nil {
+		t.Fatalf("send: %v", err)
+	}
+	if dialed != testSMTPHost+":465" {
+		t.Errorf("dialed %q, want default TLS port 465", dialed)
+	}
+	assertEmailSession(t, stub.session(t))
+}
+
+func TestSendEmailStartTLSUnsupported(t *testing.T) {
+	stub, roots := newSMTPStub(t, false, false)
+	var dialed string
+	ns := stubSender(stub, roots, &dialed)
+
+	err := ns.SendNotification(testEmailSettings(SMTPSecurityStartTLS), testEmailMessage())
+	if err == nil || !strings.Contains(err.Error(), "STARTTLS") {
+		t.Fatalf("send error = %v, want STARTTLS not supported", err)
+	}
+	if session := stub.session(t); session.auth != "" || session.data != nil {
+		t.Error("credentials or message were sent without STARTTLS")
+	}
+}
+
+func TestValidateEmailCredentialsWithoutTLS(t *testing.T) {
+	for _, tc := range []struct {
+		host    string
+		user    string
+		wantErr bool
+	}{
+		{host: testSMTPHost, user: "monitor", wantErr: true},
+		{host: testSMTPHost, user: "", wantErr: false},
+		{host: "localhost", user: "monitor", wantErr: false},
+		{host: "127.0.0.1", user: "monitor", wantErr: false},
+		{host: "::1", user: "monitor", wantErr: false},
+	} {
+		settings := testEmailSettings(SMTPSecurityNone)
+		settings.SMTPHost, settings.SMTPUsername = tc.host, tc.user
+		if tc.user == "" {
+			settings.SMTPPassword = ""
+		}
+		if err := ValidateEmail(settings); (err != nil) != tc.wantErr {
+			t.Errorf("ValidateEmail(host=%q, user=%q) = %v, want error %v", tc.host, tc.user, err, tc.wantErr)
+		}
+	}
+}
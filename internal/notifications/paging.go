@@ -0,0 +1,173 @@
+package notifications
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// Адреса API по умолчанию; api_url канала заменяет их (например, для EU-региона Opsgenie или тестового сервера).
+const (
+	DefaultPagerDutyURL = "https://events.pagerduty.com"
+	DefaultOpsgenieURL  = "https://api.opsgenie.com"
+)
+
+// opsgeniePriority сопоставляет важность проверки приоритету алерта Opsgenie.
+var opsgeniePriority = map[string]string{
+	models.SeverityCritical: "P1",
+	models.SeverityError:    "P2",
+	models.SeverityWarning:  "P3",
+	models.SeverityInfo:     "P5",
+}
+
+func severity(msg NotificationMessage) string {
+	if msg.Severity == "" {
+		return models.DefaultSeverity
+	}
+	return msg.Severity
+}
+
+// DedupKey — ключ, по которому PagerDuty (dedup_key) и Opsgenie (alias) связывают события проверки
+// в один инцидент: он зависит только от ID проверки, поэтому напоминание обновляет открытый инцидент,
+// а восстановление закрывает его.
+func DedupKey(checkID int) string {
+	return "domainpulse-check-" + strconv.Itoa(checkID)
+}
+
+// ValidatePaging проверяет настройки PagerDuty и Opsgenie: ключ интеграции (token) и необязательный api_url.
+// Уведомления о медленном ответе не поддерживаются: инцидент открывается и закрывается только по состоянию проверки.
+func ValidatePaging(settings models.NotificationSettings) error {
+	if settings.Token == "" {
+		if settings.Type == TypePagerDuty {
+			return errors.New("token (routing key) is required for pagerduty")
+		}
+		return errors.New("token (API key) is required for opsgenie")
+	}
+	if settings.APIURL != "" {
+		u, err := url.Parse(settings.APIURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return errors.New("api_url must be an absolute http or https URL")
+		}
+	}
+	if settings.NotifyOnSlowResponse {
+		return fmt.Errorf("notify_on_slow_response is not supported for %s", settings.Type)
+	}
+	return nil
+}
+
+func apiURL(settings models.NotificationSettings, fallback, path string) string {
+	base := settings.APIURL
+	if base == "" {
+		base = fallback
+	}
+	return strings.TrimRight(base, "/") + path
+}
+
+// pagingSummary — краткое описание инцидента, например "example.com (http) is DOWN: connection refused".
+func pagingSummary(msg NotificationMessage) string {
+	summary := fmt.Sprintf("%s (%s) is DOWN", msg.DomainName, msg.CheckType)
+	if msg.ErrorMessage != "" {
+		summary += ": " + msg.ErrorMessage
+	}
+	return summary
+}
+
+func pagingDetails(msg NotificationMessage) map[string]string {
+	details := make(map[string]string)
+	for _, f := range messageFields(msg) {
+		details[strings.ToLower(strings.ReplaceAll(f[0], " ", "_"))] = f[1]
+	}
+	details["check_id"] = strconv.Itoa(msg.CheckID)
+	if msg.IncidentID > 0 {
+		details["incident_id"] = strconv.Itoa(msg.IncidentID)
+	}
+	return details
+}
+
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      string            `json:"severity"`
+	Timestamp     string            `json:"timestamp,omitempty"`
+	Component     string            `json:"component,omitempty"`
+	Class         string            `json:"class,omitempty"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// sendPagerDuty отправляет событие Events v2: trigger при недоступности и напоминании, resolve при восстановлении.
+func (ns *NotificationSender) sendPagerDuty(settings models.NotificationSettings, msg NotificationMessage) error {
+	event := pagerDutyEvent{RoutingKey: settings.Token, DedupKey: DedupKey(msg.CheckID)}
+	switch msg.Event {
+	case "down", "reminder":
+		event.EventAction = "trigger"
+		event.Payload = &pagerDutyPayload{
+			Summary:       truncate(pagingSummary(msg), 1024),
+			Source:        msg.DomainName,
+			Severity:      severity(msg),
+			Timestamp:     msg.CreatedAt,
+			Component:     msg.CheckType,
+			Class:         msg.Status,
+			CustomDetails: pagingDetails(msg),
+		}
+	case "recovered":
+		event.EventAction = "resolve"
+	default:
+		return nil
+	}
+	return ns.postJSON("pagerduty", apiURL(settings, DefaultPagerDutyURL, "/v2/enqueue"), nil, event)
+}
+
+type opsgenieAlert struct {
+	Message     string            `json:"message"`
+	Alias       string            `json:"alias"`
+	Description string            `json:"description,omitempty"`
+	Priority    string            `json:"priority"`
+	Source      string            `json:"source"`
+	Entity      string            `json:"entity,omitempty"`
+	Tags        []string          `json:"tags,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+type opsgenieClose struct {
+	Source string `json:"source"`
+	Note   string `json:"note,omitempty"`
+}
+
+// sendOpsgenie создаёт алерт при недоступности и напоминании (Opsgenie объединяет алерты с одинаковым alias)
+// и закрывает его при восстановлении.
+func (ns *NotificationSender) sendOpsgenie(settings models.NotificationSettings, msg NotificationMessage) error {
+	header := http.Header{"Authorization": {"GenieKey " + settings.Token}}
+	alias := DedupKey(msg.CheckID)
+
+	switch msg.Event {
+	case "down", "reminder":
+		alert := opsgenieAlert{
+			Message:     truncate(pagingSummary(msg), 130),
+			Alias:       alias,
+			Description: formatPlainText(msg),
+			Priority:    opsgeniePriority[severity(msg)],
+			Source:      "DomainPulse",
+			Entity:      msg.DomainName,
+			Tags:        []string{"domainpulse", msg.CheckType},
+			Details:     pagingDetails(msg),
+		}
+		return ns.postJSON("opsgenie", apiURL(settings, DefaultOpsgenieURL, "/v2/alerts"), header, alert)
+	case "recovered":
+		path := "/v2/alerts/" + url.PathEscape(alias) + "/close?identifierType=alias"
+		return ns.postJSON("opsgenie", apiURL(settings, DefaultOpsgenieURL, path), header, opsgenieClose{Source: "DomainPulse", Note: "check recovered"})
+	default:
+		return nil
+	}
+}
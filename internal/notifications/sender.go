@@ -2,6 +2,7 @@ package notifications
 
 import (
 	"bytes"
+	"crypto/tls"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -10,26 +11,66 @@ import (
 	"github.com/MimoJanra/DomainPulse/internal/models"
 )
 
+// Типы каналов уведомлений.
+const (
+	TypeTelegram = "telegram"
+	TypeSlack    = "slack"
+	TypeWebhook  = "webhook"
+	TypeEmail    = "email"
+
+	TypeDiscord    = "discord"
+	TypeTeams      = "teams"
+	TypeMattermost = "mattermost"
+
+	TypePagerDuty = "pagerduty"
+	TypeOpsgenie  = "opsgenie"
+)
+
+// SupportedType сообщает, умеет ли отправитель доставлять уведомления канала этого типа.
+func SupportedType(t string) bool {
+	switch t {
+	case TypeTelegram, TypeSlack, TypeWebhook, TypeEmail, TypeDiscord, TypeTeams, TypeMattermost, TypePagerDuty, TypeOpsgenie:
+		return true
+	}
+	return false
+}
+
 type NotificationSender struct {
-	client *http.Client
+	client   *http.Client
+	dialSMTP SMTPDialFunc
+	smtpTLS  *tls.Config
 }
 
-func NewNotificationSender() *NotificationSender {
+func NewNotificationSender(timeout time.Duration) *NotificationSender {
 	return &NotificationSender{
 		client: &http.Client{
-			Timeout: 10 * time.Second,
+			Timeout: timeout,
 		},
 	}
 }
 
+// NotificationMessage — данные уведомления. Event — смена состояния проверки
+// ("down", "recovered", "reminder"); пустой для уведомлений о медленном ответе.
+// DownSince — начало недоступности, PreviousState — состояние проверки ("up" или "down")
+// до этого результата, IncidentID — открытый или закрытый этим результатом инцидент,
+// Severity — важность проверки (params.severity) для PagerDuty и Opsgenie.
 type NotificationMessage struct {
-	CheckID      int
-	DomainName   string
-	CheckType    string
-	Status       string
-	ErrorMessage string
-	DurationMS   int
-	CreatedAt    string
+	CheckID       int
+	DomainID      int
+	DomainName    string
+	CheckType     string
+	ResultID      int
+	Status        string
+	StatusCode    int
+	Outcome       string
+	Event         string
+	PreviousState string
+	DownSince     string
+	IncidentID    int
+	ErrorMessage  string
+	DurationMS    int
+	Severity      string
+	CreatedAt     string
 }
 
 func (ns *NotificationSender) SendNotification(settings models.NotificationSettings, msg NotificationMessage) error {
@@ -38,10 +79,24 @@ func (ns *NotificationSender) SendNotification(settings models.NotificationSetti
 	}
 
 	switch settings.Type {
-	case "telegram":
+	case TypeTelegram:
 		return ns.sendTelegram(settings, msg)
-	case "slack":
+	case TypeSlack:
 		return ns.sendSlack(settings, msg)
+	case TypeWebhook:
+		return ns.sendWebhook(settings, msg)
+	case TypeEmail:
+		return ns.sendEmail(settings, msg)
+	case TypeDiscord:
+		return ns.postJSON("discord", settings.WebhookURL, nil, formatDiscordMessage(settings, msg))
+	case TypeTeams:
+		return ns.postJSON("teams", settings.WebhookURL, nil, formatTeamsMessage(msg))
+	case TypeMattermost:
+		return ns.postJSON("mattermost", settings.WebhookURL, nil, formatMattermostMessage(settings, msg))
+	case TypePagerDuty:
+		return ns.sendPagerDuty(settings, msg)
+	case TypeOpsgenie:
+		return ns.sendOpsgenie(settings, msg)
 	default:
 		return fmt.Errorf("unsupported notification type: %s", settings.Type)
 	}
@@ -119,20 +174,67 @@ func (ns *NotificationSender) sendSlack(settings models.NotificationSettings, ms
 	return nil
 }
 
+func statusEmoji(msg NotificationMessage) string {
+	switch {
+	case msg.Event == "recovered":
+		return "✅"
+	case msg.Status == "slow_response":
+		return "⚠️"
+	case msg.Status == "error" || msg.Status == "timeout" || msg.Status == "failure":
+		return "❌"
+	default:
+		return "✅"
+	}
+}
+
+// eventTitle — заголовок уведомления о смене состояния.
+func eventTitle(msg NotificationMessage) string {
+	switch msg.Event {
+	case "down":
+		return "DOWN"
+	case "recovered":
+		return "RECOVERED"
+	case "reminder":
+		return "STILL DOWN"
+	default:
+		return ""
+	}
+}
+
+// messageFields — поля уведомления (название и значение) в порядке вывода для каналов
+// с табличным форматированием.
+func messageFields(msg NotificationMessage) [][2]string {
+	fields := [][2]string{
+		{"Domain", msg.DomainName},
+		{"Type", msg.CheckType},
+		{"Status", msg.Status},
+		{"Duration", fmt.Sprintf("%d ms", msg.DurationMS)},
+	}
+	if msg.DownSince != "" {
+		fields = append(fields, [2]string{"Down since", msg.DownSince})
+	}
+	if msg.ErrorMessage != "" {
+		fields = append(fields, [2]string{"Error", msg.ErrorMessage})
+	}
+	return append(fields, [2]string{"Time", msg.CreatedAt})
+}
+
 func (ns *NotificationSender) formatTelegramMessage(msg NotificationMessage) string {
-	statusEmoji := "✅"
-	if msg.Status == "error" || msg.Status == "timeout" {
-		statusEmoji = "❌"
-	} else if msg.Status == "slow_response" {
-		statusEmoji = "⚠️"
+	title := "Domain Check"
+	if event := eventTitle(msg); event != "" {
+		title += ": " + event
 	}
 
-	text := fmt.Sprintf("<b>%s Domain Check</b>\n\n", statusEmoji)
+	text := fmt.Sprintf("<b>%s %s</b>\n\n", statusEmoji(msg), title)
 	text += fmt.Sprintf("<b>Domain:</b> %s\n", msg.DomainName)
 	text += fmt.Sprintf("<b>Type:</b> %s\n", msg.CheckType)
 	text += fmt.Sprintf("<b>Status:</b> %s\n", msg.Status)
 	text += fmt.Sprintf("<b>Duration:</b> %d ms\n", msg.DurationMS)
 
+	if msg.DownSince != "" {
+		text += fmt.Sprintf("<b>Down since:</b> %s\n", msg.DownSince)
+	}
+
 	if msg.ErrorMessage != "" {
 		text += fmt.Sprintf("<b>Error:</b> %s\n", msg.ErrorMessage)
 	}
@@ -143,19 +245,21 @@ func (ns *NotificationSender) formatTelegramMessage(msg NotificationMessage) str
 }
 
 func (ns *NotificationSender) formatSlackMessage(msg NotificationMessage) string {
-	statusEmoji := "✅"
-	if msg.Status == "error" || msg.Status == "timeout" {
-		statusEmoji = "❌"
-	} else if msg.Status == "slow_response" {
-		statusEmoji = "⚠️"
+	title := "Domain Check Report"
+	if event := eventTitle(msg); event != "" {
+		title += ": " + event
 	}
 
-	text := fmt.Sprintf("%s *Domain Check Report*\n\n", statusEmoji)
+	text := fmt.Sprintf("%s *%s*\n\n", statusEmoji(msg), title)
 	text += fmt.Sprintf("*Domain:* %s\n", msg.DomainName)
 	text += fmt.Sprintf("*Type:* %s\n", msg.CheckType)
 	text += fmt.Sprintf("*Status:* %s\n", msg.Status)
 	text += fmt.Sprintf("*Duration:* %d ms\n", msg.DurationMS)
 
+	if msg.DownSince != "" {
+		text += fmt.Sprintf("*Down since:* %s\n", msg.DownSince)
+	}
+
 	if msg.ErrorMessage != "" {
 		text += fmt.Sprintf("*Error:* %s\n", msg.ErrorMessage)
 	}
@@ -0,0 +1,203 @@
+package notifications
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+
+	"golang.org/x/net/http/httpguts"
+)
+
+// WebhookEventVersion — версия формата WebhookEvent. Новые поля добавляются без смены версии,
+// версия повышается только при несовместимых изменениях.
+const WebhookEventVersion = 1
+
+// Заголовки запроса вебхука. Подпись — HMAC-SHA256 от "<timestamp>.<тело>" ключом secret
+// в виде "sha256=<hex>"; получатель отклоняет запросы с устаревшим timestamp, защищаясь от повтора.
+const (
+	HeaderWebhookEvent     = "X-DomainPulse-Event"
+	HeaderWebhookDelivery  = "X-DomainPulse-Delivery"
+	HeaderWebhookTimestamp = "X-DomainPulse-Timestamp"
+	HeaderWebhookSignature = "X-DomainPulse-Signature"
+)
+
+// Типы событий вебхука.
+const (
+	WebhookEventDown         = "check.down"
+	WebhookEventRecovered    = "check.recovered"
+	WebhookEventStillDown    = "check.still_down"
+	WebhookEventSlowResponse = "check.slow_response"
+)
+
+// WebhookEvent — тело запроса вебхука.
+type WebhookEvent struct {
+	Version       int           `json:"version"`
+	ID            string        `json:"id"`
+	Type          string        `json:"type"`
+	OccurredAt    string        `json:"occurred_at"`
+	Check         WebhookCheck  `json:"check"`
+	Domain        WebhookDomain `json:"domain"`
+	Result        WebhookResult `json:"result"`
+	PreviousState string        `json:"previous_state"`
+	State         string        `json:"state"`
+	DownSince     string        `json:"down_since,omitempty"`
+	IncidentID    int           `json:"incident_id,omitempty"`
+}
+
+type WebhookCheck struct {
+	ID   int    `json:"id"`
+	Type string `json:"type"`
+}
+
+type WebhookDomain struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type WebhookResult struct {
+	ID           int    `json:"id,omitempty"`
+	Status       string `json:"status"`
+	StatusCode   int    `json:"status_code,omitempty"`
+	Outcome      string `json:"outcome,omitempty"`
+	DurationMS   int    `json:"duration_ms"`
+	ErrorMessage string `json:"error_message,omitempty"`
+	CreatedAt    string `json:"created_at"`
+}
+
+// NewWebhookEvent собирает событие вебхука из уведомления.
+func NewWebhookEvent(msg NotificationMessage) (WebhookEvent, error) {
+	id := make([]byte, 16)
+	if _, err := rand.Read(id); err != nil {
+		return WebhookEvent{}, fmt.Errorf("generate event id: %w", err)
+	}
+
+	event := WebhookEvent{
+		Version:    WebhookEventVersion,
+		ID:         hex.EncodeToString(id),
+		OccurredAt: msg.CreatedAt,
+		Check:      WebhookCheck{ID: msg.CheckID, Type: msg.CheckType},
+		Domain:     WebhookDomain{ID: msg.DomainID, Name: msg.DomainName},
+		Result: WebhookResult{
+			ID:           msg.ResultID,
+			Status:       msg.Status,
+			StatusCode:   msg.StatusCode,
+			Outcome:      msg.Outcome,
+			DurationMS:   msg.DurationMS,
+			ErrorMessage: msg.ErrorMessage,
+			CreatedAt:    msg.CreatedAt,
+		},
+		PreviousState: msg.PreviousState,
+		State:         msg.PreviousState,
+		DownSince:     msg.DownSince,
+		IncidentID:    msg.IncidentID,
+	}
+	switch msg.Event {
+	case "down":
+		event.Type, event.State = WebhookEventDown, "down"
+	case "recovered":
+		event.Type, event.State = WebhookEventRecovered, "up"
+	case "reminder":
+		event.Type, event.State = WebhookEventStillDown, "down"
+	default:
+		event.Type = WebhookEventSlowResponse
+	}
+	return event, nil
+}
+
+// SignWebhook возвращает значение заголовка подписи для тела body, отправленного в момент timestamp (Unix, секунды).
+func SignWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// ValidateWebhook проверяет адрес и дополнительные заголовки вебхука.
+func ValidateWebhook(rawURL string, headers map[string]string) error {
+	if err := validateWebhookURL(rawURL, false); err != nil {
+		return err
+	}
+	for name, value := range headers {
+		if !httpguts.ValidHeaderFieldName(name) {
+			return fmt.Errorf("invalid header name %q", name)
+		}
+		if !httpguts.ValidHeaderFieldValue(value) {
+			return fmt.Errorf("invalid value of header %q", name)
+		}
+		switch canonical := http.CanonicalHeaderKey(name); {
+		case canonical == "Content-Type", canonical == "Content-Length", canonical == "Host",
+			strings.HasPrefix(canonical, http.CanonicalHeaderKey("X-DomainPulse-")):
+			return fmt.Errorf("header %q cannot be overridden", name)
+		}
+	}
+	return nil
+}
+
+// validateWebhookURL проверяет, что webhook_url — абсолютный http(s) адрес; httpsOnly запрещает http.
+func validateWebhookURL(rawURL string, httpsOnly bool) error {
+	u, err := url.Parse(rawURL)
+	if httpsOnly && (err != nil || u.Scheme != "https" || u.Host == "") {
+		return errors.New("webhook_url must be an absolute https URL")
+	}
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("webhook_url must be an absolute http or https URL")
+	}
+	return nil
+}
+
+func (ns *NotificationSender) sendWebhook(settings models.NotificationSettings, msg NotificationMessage) error {
+	if settings.WebhookURL == "" {
+		return fmt.Errorf("webhook webhook_url is required")
+	}
+
+	event, err := NewWebhookEvent(msg)
+	if err != nil {
+		return err
+	}
+	body, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("marshal webhook event: %w", err)
+	}
+
+	req, err := http.NewRequest("POST", settings.WebhookURL, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("create webhook request: %w", err)
+	}
+	for name, value := range settings.Headers {
+		req.Header.Set(name, value)
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookEvent, event.Type)
+	req.Header.Set(HeaderWebhookDelivery, event.ID)
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	if settings.Secret != "" {
+		req.Header.Set(HeaderWebhookSignature, SignWebhook(settings.Secret, timestamp, body))
+	}
+
+	resp, err := ns.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("send webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
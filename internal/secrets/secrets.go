@@ -0,0 +1,165 @@
+// Package secrets — шифрование секретов в БД (AES-256-GCM) с ротацией ключей и маскирование секретов в ответах API.
+//
+// Зашифрованное значение имеет вид "enc:v1:<id ключа>:<base64(nonce|шифротекст)>", где id ключа — начало
+// SHA-256 от ключа. Поэтому значение расшифровывается любым из известных ключей, а новые значения
+// всегда шифруются основным.
+package secrets
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	encryptedPrefix = "enc:v1:"
+	keySize         = 32
+)
+
+// ErrNoKey — значение зашифровано, но подходящий ключ не задан.
+var ErrNoKey = errors.New("secret is encrypted with an unknown key")
+
+type key struct {
+	id   string
+	aead cipher.AEAD
+}
+
+// Keyring шифрует секреты основным ключом и расшифровывает основным или предыдущими.
+// Методы nil Keyring работают без шифрования: значения сохраняются как есть.
+type Keyring struct {
+	primary key
+	keys    map[string]key
+}
+
+// ParseKey проверяет ключ: base64 от 32 байт (например, вывод openssl rand -base64 32).
+func ParseKey(encoded string) ([]byte, error) {
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encoded))
+	if err != nil {
+		return nil, errors.New("key must be base64-encoded")
+	}
+	if len(raw) != keySize {
+		return nil, fmt.Errorf("key must be %d bytes, got %d", keySize, len(raw))
+	}
+	return raw, nil
+}
+
+// NewKeyring создаёт набор ключей. Без основного ключа возвращает nil (шифрование выключено).
+func NewKeyring(primary string, previous []string) (*Keyring, error) {
+	if primary == "" {
+		if len(previous) > 0 {
+			return nil, errors.New("previous keys require a primary key")
+		}
+		return nil, nil
+	}
+
+	k := &Keyring{keys: make(map[string]key)}
+	for i, encoded := range append([]string{primary}, previous...) {
+		raw, err := ParseKey(encoded)
+		if err != nil {
+			return nil, err
+		}
+		block, err := aes.NewCipher(raw)
+		if err != nil {
+			return nil, err
+		}
+		aead, err := cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+		sum := sha256.Sum256(raw)
+		entry := key{id: hex.EncodeToString(sum[:4]), aead: aead}
+		if i == 0 {
+			k.primary = entry
+		}
+		if _, exists := k.keys[entry.id]; !exists {
+			k.keys[entry.id] = entry
+		}
+	}
+	return k, nil
+}
+
+// IsEncrypted сообщает, зашифровано ли значение.
+func IsEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
+
+// Encrypt шифрует значение основным ключом. Пустые значения не шифруются.
+func (k *Keyring) Encrypt(plaintext string) (string, error) {
+	if k == nil || plaintext == "" {
+		return plaintext, nil
+	}
+	nonce := make([]byte, k.primary.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := k.primary.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return encryptedPrefix + k.primary.id + ":" + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt расшифровывает значение. Незашифрованные значения (записанные до включения шифрования)
+// возвращаются как есть.
+func (k *Keyring) Decrypt(value string) (string, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	id, data, ok := strings.Cut(strings.TrimPrefix(value, encryptedPrefix), ":")
+	if !ok {
+		return "", errors.New("malformed encrypted secret")
+	}
+	if k == nil {
+		return "", ErrNoKey
+	}
+	entry, ok := k.keys[id]
+	if !ok {
+		return "", ErrNoKey
+	}
+	sealed, err := base64.StdEncoding.DecodeString(data)
+	if err != nil || len(sealed) < entry.aead.NonceSize() {
+		return "", errors.New("malformed encrypted secret")
+	}
+	nonceSize := entry.aead.NonceSize()
+	plaintext, err := entry.aead.Open(nil, sealed[:nonceSize], sealed[nonceSize:], nil)
+	if err != nil {
+		return "", errors.New("failed to decrypt secret")
+	}
+	return string(plaintext), nil
+}
+
+// NeedsReencrypt сообщает, нужно ли перешифровать сохранённое значение: оно не зашифровано
+// или зашифровано не основным ключом.
+func (k *Keyring) NeedsReencrypt(value string) bool {
+	if k == nil || value == "" {
+		return false
+	}
+	return !strings.HasPrefix(value, encryptedPrefix+k.primary.id+":")
+}
+
+// maskPlaceholder заменяет скрытую часть секрета.
+const maskPlaceholder = "********"
+
+// Mask скрывает секрет для ответа API: у длинных значений остаются видны последние 4 символа,
+// чтобы секреты можно было различать.
+func Mask(value string) string {
+	if value == "" {
+		return ""
+	}
+	runes := []rune(value)
+	if len(runes) <= 12 {
+		return maskPlaceholder
+	}
+	return maskPlaceholder + string(runes[len(runes)-4:])
+}
+
+// Unmask возвращает stored, если клиент прислал маску сохранённого секрета без изменений, иначе value.
+func Unmask(value, stored string) string {
+	if value != "" && value == Mask(stored) {
+		return stored
+	}
+	return value
+}
@@ -0,0 +1,107 @@
+package storage
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+// lastUsedResolution — как часто обновляется last_used_at ключа, чтобы не писать в БД на каждый запрос.
+const lastUsedResolution = time.Minute
+
+type APIKeyRepo struct {
+	db *sql.DB
+}
+
+func NewAPIKeyRepo(db *sql.DB) *APIKeyRepo { return &APIKeyRepo{db: db} }
+
+const apiKeyColumns = `id, user_id, name, prefix, created_at, last_used_at`
+
+type apiKeyScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanAPIKey(s apiKeyScanner) (models.APIKey, error) {
+	var (
+		k                   models.APIKey
+		createdAt, lastUsed sql.NullString
+	)
+	if err := s.Scan(&k.ID, &k.UserID, &k.Name, &k.Prefix, &createdAt, &lastUsed); err != nil {
+		return models.APIKey{}, err
+	}
+	k.CreatedAt = createdAt.String
+	k.LastUsedAt = lastUsed.String
+	return k, nil
+}
+
+// Add сохраняет ключ пользователя по его хешу; сам ключ не хранится.
+func (r *APIKeyRepo) Add(userID int, name, prefix, keyHash string) (models.APIKey, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO api_keys(user_id, name, prefix, key_hash, created_at)
+		VALUES(?, ?, ?, ?, ?)
+	`, userID, name, prefix, keyHash, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return models.APIKey{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.APIKey{}, fmt.Errorf("last insert id: %w", err)
+	}
+	return r.GetByID(int(id))
+}
+
+func (r *APIKeyRepo) GetByID(id int) (models.APIKey, error) {
+	return scanAPIKey(r.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = ?`, id))
+}
+
+func (r *APIKeyRepo) GetByUserID(userID int) ([]models.APIKey, error) {
+	rows, err := r.db.Query(`SELECT `+apiKeyColumns+` FROM api_keys WHERE user_id = ? ORDER BY id`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []models.APIKey{}
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *APIKeyRepo) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM api_keys WHERE id = ?`, id)
+	return err
+}
+
+// Authenticate находит владельца ключа по хешу и отмечает использование ключа.
+// Возвращает sql.ErrNoRows, если ключ не найден.
+func (r *APIKeyRepo) Authenticate(keyHash string) (models.User, error) {
+	var (
+		keyID     int
+		lastUsed  sql.NullString
+		u         models.User
+		createdAt sql.NullString
+	)
+	err := r.db.QueryRow(`
+		SELECT k.id, k.last_used_at, u.id, u.name, u.role, u.created_at
+		FROM api_keys k
+		JOIN users u ON u.id = k.user_id
+		WHERE k.key_hash = ?
+	`, keyHash).Scan(&keyID, &lastUsed, &u.ID, &u.Name, &u.Role, &createdAt)
+	if err != nil {
+		return models.User{}, err
+	}
+	u.CreatedAt = createdAt.String
+
+	now := time.Now()
+	if last, err := time.Parse(time.RFC3339, lastUsed.String); err != nil || now.Sub(last) >= lastUsedResolution {
+		_, _ = r.db.Exec(`UPDATE api_keys SET last_used_at = ? WHERE id = ?`, now.Format(time.RFC3339), keyID)
+	}
+	return u, nil
+}
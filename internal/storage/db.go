@@ -7,8 +7,8 @@ import (
 	_ "github.com/mattn/go-sqlite3"
 )
 
-func InitDB() (*sql.DB, error) {
-	db, err := sql.Open("sqlite3", "database.db")
+func InitDB(path string) (*sql.DB, error) {
+	db, err := sql.Open("sqlite3", path)
 	if err != nil {
 		return nil, fmt.Errorf("error open db: %w", err)
 	}
@@ -54,6 +54,14 @@ func InitDB() (*sql.DB, error) {
 		duration_ms INTEGER NOT NULL,
 		outcome TEXT,
 		error_message TEXT,
+		details TEXT,
+		dns_ms INTEGER,
+		connect_ms INTEGER,
+		tls_ms INTEGER,
+		ttfb_ms INTEGER,
+		transfer_ms INTEGER,
+		attempts INTEGER NOT NULL DEFAULT 1,
+		suppressed INTEGER NOT NULL DEFAULT 0,
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 	`)
@@ -79,11 +87,128 @@ func InitDB() (*sql.DB, error) {
 		return nil, fmt.Errorf("error creating notification_settings table: %w", err)
 	}
 
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS check_states (
+		check_id INTEGER PRIMARY KEY REFERENCES checks(id) ON DELETE CASCADE,
+		state TEXT NOT NULL,
+		since TIMESTAMP NOT NULL,
+		last_notified_at TIMESTAMP,
+		consecutive_failures INTEGER NOT NULL DEFAULT 0
+	);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error creating check_states table: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS incidents (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		check_id INTEGER NOT NULL REFERENCES checks(id) ON DELETE CASCADE,
+		status TEXT NOT NULL DEFAULT 'open',
+		cause TEXT,
+		started_at TIMESTAMP NOT NULL,
+		acknowledged_at TIMESTAMP,
+		acknowledged_by TEXT,
+		ack_note TEXT,
+		resolved_at TIMESTAMP,
+		resolution_note TEXT
+	);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error creating incidents table: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS incident_results (
+		incident_id INTEGER NOT NULL REFERENCES incidents(id) ON DELETE CASCADE,
+		result_id INTEGER NOT NULL REFERENCES results(id) ON DELETE CASCADE,
+		PRIMARY KEY (incident_id, result_id)
+	);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error creating incident_results table: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS check_dependencies (
+		check_id INTEGER NOT NULL REFERENCES checks(id) ON DELETE CASCADE,
+		depends_on_id INTEGER NOT NULL REFERENCES checks(id) ON DELETE CASCADE,
+		PRIMARY KEY (check_id, depends_on_id)
+	);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error creating check_dependencies table: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS maintenance_windows (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL DEFAULT '',
+		scope TEXT NOT NULL,
+		check_id INTEGER,
+		domain_id INTEGER,
+		starts_at TIMESTAMP,
+		ends_at TIMESTAMP,
+		cron TEXT,
+		duration_minutes INTEGER NOT NULL DEFAULT 0,
+		timezone TEXT,
+		mode TEXT NOT NULL DEFAULT 'skip',
+		enabled INTEGER NOT NULL DEFAULT 1,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error creating maintenance_windows table: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS users (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		name TEXT NOT NULL UNIQUE,
+		role TEXT NOT NULL,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error creating users table: %w", err)
+	}
+
+	_, err = db.Exec(`
+	CREATE TABLE IF NOT EXISTS api_keys (
+		id INTEGER PRIMARY KEY AUTOINCREMENT,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		name TEXT NOT NULL DEFAULT '',
+		prefix TEXT NOT NULL,
+		key_hash TEXT NOT NULL UNIQUE,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_used_at TIMESTAMP
+	);
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("error creating api_keys table: %w", err)
+	}
+
 	_, _ = db.Exec(`ALTER TABLE notification_settings ADD COLUMN notify_on_slow_response INTEGER NOT NULL DEFAULT 0`)
 	_, _ = db.Exec(`ALTER TABLE notification_settings ADD COLUMN slow_response_threshold_ms INTEGER NOT NULL DEFAULT 0`)
+	_, _ = db.Exec(`ALTER TABLE notification_settings ADD COLUMN secret TEXT`)
+	_, _ = db.Exec(`ALTER TABLE notification_settings ADD COLUMN headers TEXT`)
+	for _, column := range []string{"smtp_host", "smtp_security", "smtp_username", "smtp_password", "email_from", "email_to", "username", "channel", "api_url"} {
+		_, _ = db.Exec(`ALTER TABLE notification_settings ADD COLUMN ` + column + ` TEXT`)
+	}
+	_, _ = db.Exec(`ALTER TABLE notification_settings ADD COLUMN smtp_port INTEGER`)
+
+	_, _ = db.Exec(`ALTER TABLE results ADD COLUMN details TEXT`)
+	for _, column := range []string{"dns_ms", "connect_ms", "tls_ms", "ttfb_ms", "transfer_ms"} {
+		_, _ = db.Exec(`ALTER TABLE results ADD COLUMN ` + column + ` INTEGER`)
+	}
+	_, _ = db.Exec(`ALTER TABLE results ADD COLUMN attempts INTEGER NOT NULL DEFAULT 1`)
+	_, _ = db.Exec(`ALTER TABLE results ADD COLUMN suppressed INTEGER NOT NULL DEFAULT 0`)
 
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_results_check_created ON results(check_id, created_at)`)
 	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_checks_domain ON checks(domain_id)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_incidents_check_started ON incidents(check_id, started_at)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_check_dependencies_parent ON check_dependencies(depends_on_id)`)
+	_, _ = db.Exec(`CREATE INDEX IF NOT EXISTS idx_api_keys_user ON api_keys(user_id)`)
 
 	return db, nil
 }
@@ -0,0 +1,144 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"sort"
+)
+
+// ErrDependencyCycle — новая зависимость замкнула бы граф проверок в цикл.
+var ErrDependencyCycle = errors.New("dependency cycle detected")
+
+type DependencyRepo struct {
+	db *sql.DB
+}
+
+func NewDependencyRepo(db *sql.DB) *DependencyRepo { return &DependencyRepo{db: db} }
+
+// GetParents возвращает ID проверок, от которых зависит проверка.
+func (r *DependencyRepo) GetParents(checkID int) ([]int, error) {
+	return r.queryIDs(`
+		SELECT d.depends_on_id
+		FROM check_dependencies d
+		JOIN checks c ON c.id = d.depends_on_id
+		WHERE d.check_id = ?
+		ORDER BY d.depends_on_id
+	`, checkID)
+}
+
+// GetChildren возвращает ID проверок, которые зависят от проверки.
+func (r *DependencyRepo) GetChildren(checkID int) ([]int, error) {
+	return r.queryIDs(`
+		SELECT d.check_id
+		FROM check_dependencies d
+		JOIN checks c ON c.id = d.check_id
+		WHERE d.depends_on_id = ?
+		ORDER BY d.check_id
+	`, checkID)
+}
+
+func (r *DependencyRepo) queryIDs(query string, args ...any) ([]int, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := []int{}
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+// SetParents заменяет список родительских проверок. Возвращает ErrDependencyCycle,
+// если проверка прямо или через другие проверки стала бы зависеть сама от себя.
+func (r *DependencyRepo) SetParents(checkID int, parentIDs []int) error {
+	parentIDs = uniqueSortedIDs(parentIDs)
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	graph, err := loadDependencyGraph(tx)
+	if err != nil {
+		return err
+	}
+	graph[checkID] = parentIDs
+	if hasDependencyPath(graph, parentIDs, checkID) {
+		return ErrDependencyCycle
+	}
+
+	if _, err := tx.Exec(`DELETE FROM check_dependencies WHERE check_id = ?`, checkID); err != nil {
+		return err
+	}
+	for _, parentID := range parentIDs {
+		if _, err := tx.Exec(`INSERT INTO check_dependencies(check_id, depends_on_id) VALUES(?, ?)`, checkID, parentID); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// DeleteByCheckID удаляет все зависимости, в которых участвует проверка.
+func (r *DependencyRepo) DeleteByCheckID(checkID int) error {
+	_, err := r.db.Exec(`DELETE FROM check_dependencies WHERE check_id = ? OR depends_on_id = ?`, checkID, checkID)
+	return err
+}
+
+func loadDependencyGraph(tx *sql.Tx) (map[int][]int, error) {
+	rows, err := tx.Query(`SELECT check_id, depends_on_id FROM check_dependencies`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	graph := make(map[int][]int)
+	for rows.Next() {
+		var checkID, parentID int
+		if err := rows.Scan(&checkID, &parentID); err != nil {
+			return nil, err
+		}
+		graph[checkID] = append(graph[checkID], parentID)
+	}
+	return graph, rows.Err()
+}
+
+// hasDependencyPath проверяет, достижима ли target из любой проверки from по рёбрам "зависит от".
+func hasDependencyPath(graph map[int][]int, from []int, target int) bool {
+	visited := make(map[int]bool)
+	stack := append([]int(nil), from...)
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if id == target {
+			return true
+		}
+		if visited[id] {
+			continue
+		}
+		visited[id] = true
+		stack = append(stack, graph[id]...)
+	}
+	return false
+}
+
+// uniqueSortedIDs убирает повторы из списка ID.
+func uniqueSortedIDs(ids []int) []int {
+	seen := make(map[int]bool, len(ids))
+	out := []int{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			out = append(out, id)
+		}
+	}
+	sort.Ints(out)
+	return out
+}
@@ -0,0 +1,221 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+const (
+	IncidentOpen         = "open"
+	IncidentAcknowledged = "acknowledged"
+	IncidentResolved     = "resolved"
+)
+
+// ErrIncidentResolved — попытка изменить уже закрытый инцидент.
+var ErrIncidentResolved = errors.New("incident already resolved")
+
+type IncidentRepo struct {
+	db *sql.DB
+}
+
+func NewIncidentRepo(db *sql.DB) *IncidentRepo { return &IncidentRepo{db: db} }
+
+const incidentColumns = `id, check_id, status, cause, started_at, acknowledged_at, acknowledged_by, ack_note, resolved_at, resolution_note,
+	(SELECT COUNT(*) FROM incident_results ir WHERE ir.incident_id = incidents.id)`
+
+type incidentScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanIncident(s incidentScanner) (models.Incident, error) {
+	var (
+		inc                          models.Incident
+		cause, ackAt, ackBy, ackNote sql.NullString
+		resolvedAt, resolutionNote   sql.NullString
+	)
+	if err := s.Scan(&inc.ID, &inc.CheckID, &inc.Status, &cause, &inc.StartedAt, &ackAt, &ackBy, &ackNote, &resolvedAt, &resolutionNote, &inc.ResultCount); err != nil {
+		return models.Incident{}, err
+	}
+	inc.Cause = cause.String
+	inc.AcknowledgedAt = ackAt.String
+	inc.AcknowledgedBy = ackBy.String
+	inc.AckNote = ackNote.String
+	inc.ResolvedAt = resolvedAt.String
+	inc.ResolutionNote = resolutionNote.String
+	inc.DurationSeconds = incidentDuration(inc, time.Now())
+	return inc, nil
+}
+
+// incidentDuration считает длительность до закрытия, а для открытого инцидента — до текущего момента.
+func incidentDuration(inc models.Incident, now time.Time) int64 {
+	started, err := time.Parse(time.RFC3339, inc.StartedAt)
+	if err != nil {
+		return 0
+	}
+	end := now
+	if inc.ResolvedAt != "" {
+		if resolved, err := time.Parse(time.RFC3339, inc.ResolvedAt); err == nil {
+			end = resolved
+		}
+	}
+	return int64(end.Sub(started).Seconds())
+}
+
+// Open создаёт инцидент и связывает с ним результат, который его вызвал.
+func (r *IncidentRepo) Open(checkID int, startedAt, cause string, resultID int) (models.Incident, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO incidents(check_id, status, cause, started_at)
+		VALUES(?, ?, ?, ?)
+	`, checkID, IncidentOpen, cause, startedAt)
+	if err != nil {
+		return models.Incident{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.Incident{}, fmt.Errorf("last insert id: %w", err)
+	}
+	if resultID > 0 {
+		if err := r.AddResult(int(id), resultID); err != nil {
+			return models.Incident{}, err
+		}
+	}
+	return r.GetByID(int(id))
+}
+
+func (r *IncidentRepo) AddResult(incidentID, resultID int) error {
+	_, err := r.db.Exec(`INSERT OR IGNORE INTO incident_results(incident_id, result_id) VALUES(?, ?)`, incidentID, resultID)
+	return err
+}
+
+func (r *IncidentRepo) GetByID(id int) (models.Incident, error) {
+	row := r.db.QueryRow(`SELECT `+incidentColumns+` FROM incidents WHERE id = ?`, id)
+	return scanIncident(row)
+}
+
+// GetActiveByCheckID возвращает незакрытый инцидент проверки; found=false, если его нет.
+func (r *IncidentRepo) GetActiveByCheckID(checkID int) (inc models.Incident, found bool, err error) {
+	row := r.db.QueryRow(`
+		SELECT `+incidentColumns+`
+		FROM incidents
+		WHERE check_id = ? AND status != ?
+		ORDER BY started_at DESC
+		LIMIT 1
+	`, checkID, IncidentResolved)
+	inc, err = scanIncident(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.Incident{}, false, nil
+	}
+	if err != nil {
+		return models.Incident{}, false, err
+	}
+	return inc, true, nil
+}
+
+func (r *IncidentRepo) Acknowledge(id int, at, by, note string) error {
+	res, err := r.db.Exec(`
+		UPDATE incidents
+		SET status = ?, acknowledged_at = ?, acknowledged_by = ?, ack_note = ?
+		WHERE id = ? AND status != ?
+	`, IncidentAcknowledged, at, by, note, id, IncidentResolved)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+func (r *IncidentRepo) Resolve(id int, at, note string) error {
+	res, err := r.db.Exec(`
+		UPDATE incidents
+		SET status = ?, resolved_at = ?, resolution_note = ?
+		WHERE id = ? AND status != ?
+	`, IncidentResolved, at, note, id, IncidentResolved)
+	if err != nil {
+		return err
+	}
+	return requireAffected(res)
+}
+
+func requireAffected(res sql.Result) error {
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return ErrIncidentResolved
+	}
+	return nil
+}
+
+// List возвращает инциденты (новые первыми) с фильтрацией по проверке и статусу.
+func (r *IncidentRepo) List(checkID *int, status string, page, pageSize int) ([]models.Incident, int, error) {
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = 50
+	}
+	if pageSize > 1000 {
+		pageSize = 1000
+	}
+
+	where := " WHERE 1=1"
+	args := []any{}
+	if checkID != nil {
+		where += " AND check_id = ?"
+		args = append(args, *checkID)
+	}
+	if status != "" {
+		where += " AND status = ?"
+		args = append(args, status)
+	}
+
+	var total int
+	if err := r.db.QueryRow("SELECT COUNT(*) FROM incidents"+where, args...).Scan(&total); err != nil {
+		return nil, 0, err
+	}
+
+	query := "SELECT " + incidentColumns + " FROM incidents" + where + " ORDER BY started_at DESC, id DESC LIMIT ? OFFSET ?"
+	rows, err := r.db.Query(query, append(args, pageSize, (page-1)*pageSize)...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	incidents := []models.Incident{}
+	for rows.Next() {
+		inc, err := scanIncident(rows)
+		if err != nil {
+			return nil, 0, err
+		}
+		incidents = append(incidents, inc)
+	}
+	return incidents, total, rows.Err()
+}
+
+// GetResults возвращает результаты, связанные с инцидентом, в хронологическом порядке.
+func (r *IncidentRepo) GetResults(incidentID int) ([]models.Result, error) {
+	rows, err := r.db.Query(`
+		SELECT `+resultColumns+`
+		FROM results
+		WHERE id IN (SELECT result_id FROM incident_results WHERE incident_id = ?)
+		ORDER BY created_at ASC, id ASC
+	`, incidentID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	results := []models.Result{}
+	for rows.Next() {
+		res, err := scanResult(rows)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, res)
+	}
+	return results, rows.Err()
+}
@@ -0,0 +1,383 @@
+package storage
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/cron"
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+const (
+	MaintenanceScopeCheck  = "check"
+	MaintenanceScopeDomain = "domain"
+	MaintenanceScopeGlobal = "global"
+
+	// MaintenanceModeSkip — проверки не запускаются, MaintenanceModeMark — результаты
+	// сохраняются со статусом maintenance.
+	MaintenanceModeSkip = "skip"
+	MaintenanceModeMark = "mark"
+
+	// StatusMaintenance — статус результата, полученного во время окна в режиме mark.
+	StatusMaintenance = "maintenance"
+)
+
+type MaintenanceRepo struct {
+	db *sql.DB
+}
+
+func NewMaintenanceRepo(db *sql.DB) *MaintenanceRepo { return &MaintenanceRepo{db: db} }
+
+const maintenanceColumns = `id, name, scope, check_id, domain_id, starts_at, ends_at, cron, duration_minutes, timezone, mode, enabled, created_at`
+
+type maintenanceScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanMaintenanceWindow(s maintenanceScanner) (models.MaintenanceWindow, error) {
+	var (
+		mw                               models.MaintenanceWindow
+		checkID, domainID                sql.NullInt64
+		startsAt, endsAt, cronExpr, zone sql.NullString
+		createdAt                        sql.NullString
+		enabledInt                       int
+	)
+	if err := s.Scan(&mw.ID, &mw.Name, &mw.Scope, &checkID, &domainID, &startsAt, &endsAt, &cronExpr, &mw.DurationMinutes, &zone, &mw.Mode, &enabledInt, &createdAt); err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	mw.CheckID = int(checkID.Int64)
+	mw.DomainID = int(domainID.Int64)
+	mw.StartsAt = startsAt.String
+	mw.EndsAt = endsAt.String
+	mw.Cron = cronExpr.String
+	mw.Timezone = zone.String
+	mw.Enabled = enabledInt == 1
+	mw.CreatedAt = createdAt.String
+	return mw, nil
+}
+
+func nullString(s string) sql.NullString {
+	return sql.NullString{String: s, Valid: s != ""}
+}
+
+func nullID(id int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(id), Valid: id > 0}
+}
+
+func (r *MaintenanceRepo) Add(mw models.MaintenanceWindow) (models.MaintenanceWindow, error) {
+	res, err := r.db.Exec(`
+		INSERT INTO maintenance_windows(name, scope, check_id, domain_id, starts_at, ends_at, cron, duration_minutes, timezone, mode, enabled, created_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, mw.Name, mw.Scope, nullID(mw.CheckID), nullID(mw.DomainID), nullString(mw.StartsAt), nullString(mw.EndsAt),
+		nullString(mw.Cron), mw.DurationMinutes, nullString(mw.Timezone), mw.Mode, boolToInt(mw.Enabled), time.Now().Format(time.RFC3339))
+	if err != nil {
+		return models.MaintenanceWindow{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.MaintenanceWindow{}, fmt.Errorf("last insert id: %w", err)
+	}
+	return r.GetByID(int(id))
+}
+
+func (r *MaintenanceRepo) Update(id int, mw models.MaintenanceWindow) error {
+	_, err := r.db.Exec(`
+		UPDATE maintenance_windows
+		SET name = ?, scope = ?, check_id = ?, domain_id = ?, starts_at = ?, ends_at = ?, cron = ?, duration_minutes = ?, timezone = ?, mode = ?, enabled = ?
+		WHERE id = ?
+	`, mw.Name, mw.Scope, nullID(mw.CheckID), nullID(mw.DomainID), nullString(mw.StartsAt), nullString(mw.EndsAt),
+		nullString(mw.Cron), mw.DurationMinutes, nullString(mw.Timezone), mw.Mode, boolToInt(mw.Enabled), id)
+	return err
+}
+
+func (r *MaintenanceRepo) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM maintenance_windows WHERE id = ?`, id)
+	return err
+}
+
+func (r *MaintenanceRepo) GetByID(id int) (models.MaintenanceWindow, error) {
+	row := r.db.QueryRow(`SELECT `+maintenanceColumns+` FROM maintenance_windows WHERE id = ?`, id)
+	return scanMaintenanceWindow(row)
+}
+
+func (r *MaintenanceRepo) GetAll() ([]models.MaintenanceWindow, error) {
+	return queryMaintenanceWindows(r.db, `SELECT `+maintenanceColumns+` FROM maintenance_windows ORDER BY id`)
+}
+
+// GetForCheck возвращает включённые окна, которые распространяются на проверку: её собственные,
+// окна её домена и глобальные.
+func (r *MaintenanceRepo) GetForCheck(checkID, domainID int) ([]models.MaintenanceWindow, error) {
+	return queryMaintenanceWindows(r.db, `
+		SELECT `+maintenanceColumns+`
+		FROM maintenance_windows
+		WHERE enabled = 1 AND (scope = ? OR (scope = ? AND check_id = ?) OR (scope = ? AND domain_id = ?))
+		ORDER BY id
+	`, MaintenanceScopeGlobal, MaintenanceScopeCheck, checkID, MaintenanceScopeDomain, domainID)
+}
+
+// sqliteTimeLayout — формат datetime() SQLite (UTC), в котором сравниваются моменты результатов.
+const sqliteTimeLayout = "2006-01-02 15:04:05"
+
+// maintenanceRanges возвращает JSON-массив промежутков [check_id, начало, конец) окон обслуживания,
+// которые распространяются на проверку checkID (0 — на все проверки) и пересекаются с периодом [from, to].
+// check_id 0 в промежутке означает глобальное окно. Повторяющиеся окна разворачиваются по расписанию
+// только в пределах периода, чтобы результаты в окнах отбирались в SQL, без разбора cron для каждой строки.
+// Пустая строка — таких промежутков нет.
+func maintenanceRanges(db *sql.DB, checkID int, from, to *time.Time) (string, error) {
+	query := `SELECT ` + maintenanceColumns + ` FROM maintenance_windows WHERE enabled = 1 ORDER BY id`
+	var args []any
+	if checkID > 0 {
+		query = `
+			SELECT ` + maintenanceColumns + `
+			FROM maintenance_windows
+			WHERE enabled = 1 AND (scope = ? OR (scope = ? AND check_id = ?)
+				OR (scope = ? AND domain_id = (SELECT domain_id FROM checks WHERE id = ?)))
+			ORDER BY id
+		`
+		args = []any{MaintenanceScopeGlobal, MaintenanceScopeCheck, checkID, MaintenanceScopeDomain, checkID}
+	}
+	windows, err := queryMaintenanceWindows(db, query, args...)
+	if err != nil {
+		return "", err
+	}
+	schedules := NewMaintenanceSchedules(windows)
+	if len(schedules) == 0 {
+		return "", nil
+	}
+
+	upper := time.Now()
+	if to != nil {
+		upper = *to
+	}
+	var lower time.Time
+	if from != nil {
+		lower = *from
+	} else if lower, err = earliestResult(db, checkID); err != nil || lower.IsZero() {
+		return "", err
+	}
+
+	var ranges [][3]any
+	for _, s := range schedules {
+		targets := []int{checkID}
+		switch {
+		case checkID > 0 || s.Window.Scope == MaintenanceScopeGlobal:
+		case s.Window.Scope == MaintenanceScopeCheck:
+			targets = []int{s.Window.CheckID}
+		case s.Window.Scope == MaintenanceScopeDomain:
+			if targets, err = domainCheckIDs(db, s.Window.DomainID); err != nil {
+				return "", err
+			}
+		}
+		for _, r := range s.Ranges(lower, upper) {
+			for _, target := range targets {
+				ranges = append(ranges, [3]any{target, r[0].UTC().Format(sqliteTimeLayout), r[1].UTC().Format(sqliteTimeLayout)})
+			}
+		}
+	}
+	if len(ranges) == 0 {
+		return "", nil
+	}
+	data, err := json.Marshal(ranges)
+	return string(data), err
+}
+
+// earliestResult возвращает момент самого раннего результата проверки checkID (0 — всех проверок)
+// с запасом в сутки на разницу часовых поясов в created_at; нулевое время — результатов нет.
+func earliestResult(db *sql.DB, checkID int) (time.Time, error) {
+	query := `SELECT MIN(created_at) FROM results`
+	var args []any
+	if checkID > 0 {
+		query += ` WHERE check_id = ?`
+		args = append(args, checkID)
+	}
+	var earliest sql.NullString
+	if err := db.QueryRow(query, args...).Scan(&earliest); err != nil || !earliest.Valid {
+		return time.Time{}, err
+	}
+	t, err := time.Parse(time.RFC3339, earliest.String)
+	if err != nil {
+		if t, err = time.Parse(sqliteTimeLayout, earliest.String); err != nil {
+			return time.Time{}, fmt.Errorf("parse result time %q: %w", earliest.String, err)
+		}
+	}
+	return t.Add(-24 * time.Hour), nil
+}
+
+func domainCheckIDs(db *sql.DB, domainID int) ([]int, error) {
+	rows, err := db.Query(`SELECT id FROM checks WHERE domain_id = ?`, domainID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+	return ids, rows.Err()
+}
+
+func queryMaintenanceWindows(db *sql.DB, query string, args ...any) ([]models.MaintenanceWindow, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	windows := []models.MaintenanceWindow{}
+	for rows.Next() {
+		mw, err := scanMaintenanceWindow(rows)
+		if err != nil {
+			return nil, err
+		}
+		windows = append(windows, mw)
+	}
+	return windows, rows.Err()
+}
+
+// MaintenanceSchedule — окно обслуживания с разобранными временем и расписанием,
+// чтобы быстро проверять, попадает ли момент в окно.
+type MaintenanceSchedule struct {
+	Window   models.MaintenanceWindow
+	starts   time.Time
+	ends     time.Time
+	cron     *cron.Schedule
+	location *time.Location
+	duration time.Duration
+}
+
+// NewMaintenanceSchedule разбирает окно и проверяет его поля: разовое окно требует starts_at и ends_at,
+// повторяющееся — cron и duration_minutes.
+func NewMaintenanceSchedule(mw models.MaintenanceWindow) (*MaintenanceSchedule, error) {
+	s := &MaintenanceSchedule{Window: mw, location: time.UTC}
+
+	var err error
+	if mw.StartsAt != "" {
+		if s.starts, err = time.Parse(time.RFC3339, mw.StartsAt); err != nil {
+			return nil, errors.New("starts_at must be in RFC3339 format")
+		}
+	}
+	if mw.EndsAt != "" {
+		if s.ends, err = time.Parse(time.RFC3339, mw.EndsAt); err != nil {
+			return nil, errors.New("ends_at must be in RFC3339 format")
+		}
+	}
+	if !s.starts.IsZero() && !s.ends.IsZero() && !s.ends.After(s.starts) {
+		return nil, errors.New("ends_at must be after starts_at")
+	}
+
+	if mw.Cron == "" {
+		if s.starts.IsZero() || s.ends.IsZero() {
+			return nil, errors.New("one-off window requires starts_at and ends_at, recurring window requires cron")
+		}
+		return s, nil
+	}
+
+	if s.cron, err = cron.Parse(mw.Cron); err != nil {
+		return nil, fmt.Errorf("invalid cron: %w", err)
+	}
+	if mw.DurationMinutes <= 0 {
+		return nil, errors.New("duration_minutes must be positive for a recurring window")
+	}
+	s.duration = time.Duration(mw.DurationMinutes) * time.Minute
+	if mw.Timezone != "" {
+		if s.location, err = time.LoadLocation(mw.Timezone); err != nil {
+			return nil, fmt.Errorf("unknown timezone %q", mw.Timezone)
+		}
+	}
+	return s, nil
+}
+
+// Active сообщает, попадает ли момент t в окно.
+func (s *MaintenanceSchedule) Active(t time.Time) bool {
+	if !s.starts.IsZero() && t.Before(s.starts) {
+		return false
+	}
+	if !s.ends.IsZero() && !t.Before(s.ends) {
+		return false
+	}
+	if s.cron == nil {
+		return true
+	}
+	// t внутри окна, если последнее срабатывание расписания было не раньше чем duration назад.
+	occurrence := s.cron.Next(t.In(s.location).Add(-s.duration))
+	return !occurrence.IsZero() && !occurrence.After(t)
+}
+
+// Ranges возвращает промежутки [начало, конец), в которые окно действует и которые пересекаются
+// с периодом [from, to]. Пересекающиеся срабатывания повторяющегося окна объединяются.
+func (s *MaintenanceSchedule) Ranges(from, to time.Time) [][2]time.Time {
+	if s.cron == nil {
+		if s.starts.After(to) || !s.ends.After(from) {
+			return nil
+		}
+		return [][2]time.Time{{s.starts, s.ends}}
+	}
+
+	if !s.starts.IsZero() && s.starts.After(from) {
+		from = s.starts
+	}
+	if !s.ends.IsZero() && s.ends.Before(to) {
+		to = s.ends
+	}
+	var ranges [][2]time.Time
+	for occurrence := s.cron.Next(from.In(s.location).Add(-s.duration)); !occurrence.IsZero() && !occurrence.After(to); occurrence = s.cron.Next(occurrence) {
+		start, end := occurrence, occurrence.Add(s.duration)
+		if !s.starts.IsZero() && start.Before(s.starts) {
+			start = s.starts
+		}
+		if !s.ends.IsZero() && end.After(s.ends) {
+			end = s.ends
+		}
+		if !start.Before(end) {
+			continue
+		}
+		if n := len(ranges); n > 0 && !start.After(ranges[n-1][1]) {
+			if end.After(ranges[n-1][1]) {
+				ranges[n-1][1] = end
+			}
+			continue
+		}
+		ranges = append(ranges, [2]time.Time{start, end})
+	}
+	return ranges
+}
+
+// NewMaintenanceSchedules разбирает окна, пропуская некорректные.
+func NewMaintenanceSchedules(windows []models.MaintenanceWindow) []*MaintenanceSchedule {
+	schedules := make([]*MaintenanceSchedule, 0, len(windows))
+	for _, mw := range windows {
+		if s, err := NewMaintenanceSchedule(mw); err == nil {
+			schedules = append(schedules, s)
+		}
+	}
+	return schedules
+}
+
+// ActiveMaintenance возвращает окно, действующее в момент t. Если действуют несколько окон,
+// предпочтение отдаётся режиму skip.
+func ActiveMaintenance(schedules []*MaintenanceSchedule, t time.Time) (models.MaintenanceWindow, bool) {
+	var (
+		active models.MaintenanceWindow
+		found  bool
+	)
+	for _, s := range schedules {
+		if !s.Active(t) {
+			continue
+		}
+		if !found || s.Window.Mode == MaintenanceModeSkip {
+			active, found = s.Window, true
+		}
+		if active.Mode == MaintenanceModeSkip {
+			break
+		}
+	}
+	return active, found
+}
@@ -2,51 +2,112 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 
 	"github.com/MimoJanra/DomainPulse/internal/models"
+	"github.com/MimoJanra/DomainPulse/internal/notifications"
+	"github.com/MimoJanra/DomainPulse/internal/secrets"
 )
 
+// NotificationRepo хранит настройки уведомлений. Секретные поля (см. NotificationSecrets) шифруются
+// ключами keyring при записи и расшифровываются при чтении; без keyring они хранятся открыто.
 type NotificationRepo struct {
-	db *sql.DB
+	db      *sql.DB
+	keyring *secrets.Keyring
 }
 
-func NewNotificationRepo(db *sql.DB) *NotificationRepo {
-	return &NotificationRepo{db: db}
+func NewNotificationRepo(db *sql.DB, keyring *secrets.Keyring) *NotificationRepo {
+	return &NotificationRepo{db: db, keyring: keyring}
+}
+
+// NotificationSecrets возвращает указатели на секретные поля настроек: токены, адреса и ключи,
+// которые сами дают доступ к каналу. Они шифруются в БД и скрываются в ответах API.
+// Заголовки вебхука (Headers) тоже секретны, но хранятся отдельно — см. notificationRow.
+func NotificationSecrets(ns *models.NotificationSettings) []*string {
+	return []*string{&ns.Token, &ns.WebhookURL, &ns.Secret, &ns.SMTPPassword}
+}
+
+const notificationColumns = `id, type, enabled, token, chat_id, webhook_url, secret, headers,
+	smtp_host, smtp_port, smtp_security, smtp_username, smtp_password, email_from, email_to, username, channel, api_url,
+	notify_on_failure, notify_on_success, notify_on_slow_response, slow_response_threshold_ms`
+
+// notificationRow — строка notification_settings в том виде, в каком она хранится:
+// заголовки вебхука и получатели письма сериализованы в JSON; заголовки, как и остальные секреты,
+// могут быть зашифрованы.
+type notificationRow struct {
+	models.NotificationSettings
+	headers string
+	emailTo string
+}
+
+func (row *notificationRow) secretFields() []*string {
+	return append(NotificationSecrets(&row.NotificationSettings), &row.headers)
 }
 
 type notifScanner interface {
 	Scan(dest ...any) error
 }
 
-func scanNotificationSettings(s notifScanner) (models.NotificationSettings, error) {
-	var ns models.NotificationSettings
-	var token, chatID, webhookURL sql.NullString
-	var slowThreshold sql.NullInt64
-	if err := s.Scan(&ns.ID, &ns.Type, &ns.Enabled, &token, &chatID, &webhookURL, &ns.NotifyOnFailure, &ns.NotifyOnSuccess, &ns.NotifyOnSlowResponse, &slowThreshold); err != nil {
-		return models.NotificationSettings{}, err
+func scanNotificationRow(s notifScanner) (notificationRow, error) {
+	var row notificationRow
+	var token, chatID, webhookURL, secret, headers sql.NullString
+	var smtpHost, smtpSecurity, smtpUsername, smtpPassword, emailFrom, emailTo, username, channel, apiURL sql.NullString
+	var smtpPort, slowThreshold sql.NullInt64
+	if err := s.Scan(&row.ID, &row.Type, &row.Enabled, &token, &chatID, &webhookURL, &secret, &headers,
+		&smtpHost, &smtpPort, &smtpSecurity, &smtpUsername, &smtpPassword, &emailFrom, &emailTo, &username, &channel, &apiURL,
+		&row.NotifyOnFailure, &row.NotifyOnSuccess, &row.NotifyOnSlowResponse, &slowThreshold); err != nil {
+		return notificationRow{}, err
 	}
-	if token.Valid {
-		ns.Token = token.String
+	row.Token = token.String
+	row.ChatID = chatID.String
+	row.WebhookURL = webhookURL.String
+	row.Secret = secret.String
+	row.headers = headers.String
+	row.SMTPHost = smtpHost.String
+	row.SMTPPort = int(smtpPort.Int64)
+	row.SMTPSecurity = smtpSecurity.String
+	row.SMTPUsername = smtpUsername.String
+	row.SMTPPassword = smtpPassword.String
+	row.EmailFrom = emailFrom.String
+	row.emailTo = emailTo.String
+	row.Username = username.String
+	row.Channel = channel.String
+	row.APIURL = apiURL.String
+	if slowThreshold.Valid {
+		row.SlowResponseThreshold = int(slowThreshold.Int64)
 	}
-	if chatID.Valid {
-		ns.ChatID = chatID.String
+	return row, nil
+}
+
+// scan читает настройки и расшифровывает секретные поля.
+func (r *NotificationRepo) scan(s notifScanner) (models.NotificationSettings, error) {
+	row, err := scanNotificationRow(s)
+	if err != nil {
+		return models.NotificationSettings{}, err
 	}
-	if webhookURL.Valid {
-		ns.WebhookURL = webhookURL.String
+	for _, field := range row.secretFields() {
+		if *field, err = r.keyring.Decrypt(*field); err != nil {
+			return models.NotificationSettings{}, fmt.Errorf("notification settings %d: %w", row.ID, err)
+		}
 	}
-	if slowThreshold.Valid {
-		ns.SlowResponseThreshold = int(slowThreshold.Int64)
+	ns := row.NotificationSettings
+	if row.headers != "" {
+		if err := json.Unmarshal([]byte(row.headers), &ns.Headers); err != nil {
+			return models.NotificationSettings{}, fmt.Errorf("notification settings %d: invalid headers: %w", row.ID, err)
+		}
+	}
+	if row.emailTo != "" {
+		if err := json.Unmarshal([]byte(row.emailTo), &ns.EmailTo); err != nil {
+			return models.NotificationSettings{}, fmt.Errorf("notification settings %d: invalid email_to: %w", row.ID, err)
+		}
 	}
 	return ns, nil
 }
 
-func (r *NotificationRepo) GetAll() ([]models.NotificationSettings, error) {
-	rows, err := r.db.Query(`
-		SELECT id, type, enabled, token, chat_id, webhook_url, notify_on_failure, notify_on_success, notify_on_slow_response, slow_response_threshold_ms
-		FROM notification_settings
-		ORDER BY id
-	`)
+func (r *NotificationRepo) query(query string, args ...any) ([]models.NotificationSettings, error) {
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -54,7 +115,7 @@ func (r *NotificationRepo) GetAll() ([]models.NotificationSettings, error) {
 
 	var settings []models.NotificationSettings
 	for rows.Next() {
-		s, err := scanNotificationSettings(rows)
+		s, err := r.scan(rows)
 		if err != nil {
 			return nil, err
 		}
@@ -63,48 +124,64 @@ func (r *NotificationRepo) GetAll() ([]models.NotificationSettings, error) {
 	return settings, rows.Err()
 }
 
-func (r *NotificationRepo) GetByID(id int) (models.NotificationSettings, error) {
-	row := r.db.QueryRow(`
-		SELECT id, type, enabled, token, chat_id, webhook_url, notify_on_failure, notify_on_success, notify_on_slow_response, slow_response_threshold_ms
-		FROM notification_settings
-		WHERE id = ?
-	`, id)
-	return scanNotificationSettings(row)
-}
-
-func (r *NotificationRepo) GetEnabled() ([]models.NotificationSettings, error) {
-	rows, err := r.db.Query(`
-		SELECT id, type, enabled, token, chat_id, webhook_url, notify_on_failure, notify_on_success, notify_on_slow_response, slow_response_threshold_ms
-		FROM notification_settings
-		WHERE enabled = 1
-		ORDER BY id
-	`)
-	if err != nil {
-		return nil, err
+// encrypt готовит настройки к записи: сериализует заголовки и получателей и шифрует секретные поля.
+func (r *NotificationRepo) encrypt(settings models.NotificationSettings) (notificationRow, error) {
+	row := notificationRow{NotificationSettings: settings}
+	if len(settings.Headers) > 0 {
+		raw, err := json.Marshal(settings.Headers)
+		if err != nil {
+			return notificationRow{}, fmt.Errorf("marshal headers: %w", err)
+		}
+		row.headers = string(raw)
 	}
-	defer rows.Close()
-
-	var settings []models.NotificationSettings
-	for rows.Next() {
-		s, err := scanNotificationSettings(rows)
+	if len(settings.EmailTo) > 0 {
+		raw, err := json.Marshal(settings.EmailTo)
 		if err != nil {
-			return nil, err
+			return notificationRow{}, fmt.Errorf("marshal email_to: %w", err)
 		}
-		settings = append(settings, s)
+		row.emailTo = string(raw)
 	}
-	return settings, rows.Err()
+	row.Headers, row.EmailTo = nil, nil
+
+	var err error
+	for _, field := range row.secretFields() {
+		if *field, err = r.keyring.Encrypt(*field); err != nil {
+			return notificationRow{}, fmt.Errorf("encrypt notification secret: %w", err)
+		}
+	}
+	return row, nil
+}
+
+func (r *NotificationRepo) GetAll() ([]models.NotificationSettings, error) {
+	return r.query(`SELECT ` + notificationColumns + ` FROM notification_settings ORDER BY id`)
+}
+
+func (r *NotificationRepo) GetByID(id int) (models.NotificationSettings, error) {
+	row := r.db.QueryRow(`SELECT `+notificationColumns+` FROM notification_settings WHERE id = ?`, id)
+	return r.scan(row)
+}
+
+func (r *NotificationRepo) GetEnabled() ([]models.NotificationSettings, error) {
+	return r.query(`SELECT ` + notificationColumns + ` FROM notification_settings WHERE enabled = 1 ORDER BY id`)
 }
 
 func (r *NotificationRepo) Add(settings models.NotificationSettings) (models.NotificationSettings, error) {
-	if settings.Type != "telegram" && settings.Type != "slack" {
+	if !notifications.SupportedType(settings.Type) {
 		return models.NotificationSettings{}, fmt.Errorf("unsupported notification type: %s", settings.Type)
 	}
+	stored, err := r.encrypt(settings)
+	if err != nil {
+		return models.NotificationSettings{}, err
+	}
 
 	res, err := r.db.Exec(`
-		INSERT INTO notification_settings(type, enabled, token, chat_id, webhook_url, notify_on_failure, notify_on_success, notify_on_slow_response, slow_response_threshold_ms)
-		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?)
-	`, settings.Type, boolToInt(settings.Enabled), settings.Token, settings.ChatID, settings.WebhookURL,
-		boolToInt(settings.NotifyOnFailure), boolToInt(settings.NotifyOnSuccess), boolToInt(settings.NotifyOnSlowResponse), settings.SlowResponseThreshold)
+		INSERT INTO notification_settings(type, enabled, token, chat_id, webhook_url, secret, headers,
+			smtp_host, smtp_port, smtp_security, smtp_username, smtp_password, email_from, email_to, username, channel, api_url,
+			notify_on_failure, notify_on_success, notify_on_slow_response, slow_response_threshold_ms)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, stored.Type, boolToInt(stored.Enabled), stored.Token, stored.ChatID, stored.WebhookURL, stored.Secret, stored.headers,
+		stored.SMTPHost, stored.SMTPPort, stored.SMTPSecurity, stored.SMTPUsername, stored.SMTPPassword, stored.EmailFrom, stored.emailTo, stored.Username, stored.Channel, stored.APIURL,
+		boolToInt(stored.NotifyOnFailure), boolToInt(stored.NotifyOnSuccess), boolToInt(stored.NotifyOnSlowResponse), stored.SlowResponseThreshold)
 	if err != nil {
 		return models.NotificationSettings{}, err
 	}
@@ -117,19 +194,87 @@ func (r *NotificationRepo) Add(settings models.NotificationSettings) (models.Not
 }
 
 func (r *NotificationRepo) Update(id int, settings models.NotificationSettings) error {
-	if settings.Type != "telegram" && settings.Type != "slack" {
+	if !notifications.SupportedType(settings.Type) {
 		return fmt.Errorf("unsupported notification type: %s", settings.Type)
 	}
+	stored, err := r.encrypt(settings)
+	if err != nil {
+		return err
+	}
+	return r.write(id, stored)
+}
 
+// write сохраняет настройки, секретные поля которых уже подготовлены к записи.
+func (r *NotificationRepo) write(id int, stored notificationRow) error {
 	_, err := r.db.Exec(`
 		UPDATE notification_settings
-		SET type = ?, enabled = ?, token = ?, chat_id = ?, webhook_url = ?, notify_on_failure = ?, notify_on_success = ?, notify_on_slow_response = ?, slow_response_threshold_ms = ?
+		SET type = ?, enabled = ?, token = ?, chat_id = ?, webhook_url = ?, secret = ?, headers = ?,
+			smtp_host = ?, smtp_port = ?, smtp_security = ?, smtp_username = ?, smtp_password = ?, email_from = ?, email_to = ?,
+			username = ?, channel = ?, api_url = ?,
+			notify_on_failure = ?, notify_on_success = ?, notify_on_slow_response = ?, slow_response_threshold_ms = ?
 		WHERE id = ?
-	`, settings.Type, boolToInt(settings.Enabled), settings.Token, settings.ChatID, settings.WebhookURL,
-		boolToInt(settings.NotifyOnFailure), boolToInt(settings.NotifyOnSuccess), boolToInt(settings.NotifyOnSlowResponse), settings.SlowResponseThreshold, id)
+	`, stored.Type, boolToInt(stored.Enabled), stored.Token, stored.ChatID, stored.WebhookURL, stored.Secret, stored.headers,
+		stored.SMTPHost, stored.SMTPPort, stored.SMTPSecurity, stored.SMTPUsername, stored.SMTPPassword, stored.EmailFrom, stored.emailTo, stored.Username, stored.Channel, stored.APIURL,
+		boolToInt(stored.NotifyOnFailure), boolToInt(stored.NotifyOnSuccess), boolToInt(stored.NotifyOnSlowResponse), stored.SlowResponseThreshold, id)
 	return err
 }
 
+// EncryptSecrets шифрует основным ключом секреты, которые хранятся открыто или зашифрованы прежним ключом,
+// и возвращает число обновлённых настроек. Вызывается при запуске: так включается шифрование
+// для существующих данных и завершается ротация ключа. Без ключа только проверяет,
+// что в БД нет зашифрованных секретов, которые нельзя будет прочитать.
+func (r *NotificationRepo) EncryptSecrets() (int, error) {
+	rows, err := r.db.Query(`SELECT ` + notificationColumns + ` FROM notification_settings ORDER BY id`)
+	if err != nil {
+		return 0, err
+	}
+	var raw []notificationRow
+	for rows.Next() {
+		ns, err := scanNotificationRow(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		raw = append(raw, ns)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	updated := 0
+	for _, ns := range raw {
+		changed := false
+		for _, field := range ns.secretFields() {
+			if r.keyring == nil {
+				if secrets.IsEncrypted(*field) {
+					return updated, errors.New("notification secrets are encrypted but no secrets key is configured")
+				}
+				continue
+			}
+			if !r.keyring.NeedsReencrypt(*field) {
+				continue
+			}
+			plaintext, err := r.keyring.Decrypt(*field)
+			if err != nil {
+				return updated, fmt.Errorf("notification settings %d: %w", ns.ID, err)
+			}
+			if *field, err = r.keyring.Encrypt(plaintext); err != nil {
+				return updated, fmt.Errorf("encrypt notification secret: %w", err)
+			}
+			changed = true
+		}
+		if !changed {
+			continue
+		}
+		if err := r.write(ns.ID, ns); err != nil {
+			return updated, err
+		}
+		updated++
+	}
+	return updated, nil
+}
+
 func (r *NotificationRepo) Delete(id int) error {
 	_, err := r.db.Exec(`DELETE FROM notification_settings WHERE id = ?`, id)
 	return err
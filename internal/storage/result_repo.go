@@ -2,6 +2,8 @@ package storage
 
 import (
 	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"sort"
 	"time"
@@ -15,22 +17,91 @@ type ResultRepo struct {
 
 func NewResultRepo(db *sql.DB) *ResultRepo { return &ResultRepo{db: db} }
 
-func (r *ResultRepo) Add(res models.Result) error {
+// StatusSkipped — статус результата, записанного вместо запуска, который был отброшен
+// из-за переполнения очереди задач.
+const StatusSkipped = "skipped"
+
+const resultColumns = "id, check_id, status, status_code, duration_ms, outcome, error_message, details, " +
+	"dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms, attempts, suppressed, created_at"
+
+// phaseNames — ключи фаз HTTP запроса в PhaseStats, в порядке колонок dns_ms..transfer_ms.
+var phaseNames = []string{"dns", "connect", "tls", "ttfb", "transfer"}
+
+type resultScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanResult(s resultScanner) (models.Result, error) {
+	var (
+		res         models.Result
+		detailsJSON sql.NullString
+		phases      [5]sql.NullInt64
+		suppressed  int
+	)
+	if err := s.Scan(&res.ID, &res.CheckID, &res.Status, &res.StatusCode, &res.DurationMS, &res.Outcome, &res.ErrorMessage, &detailsJSON,
+		&phases[0], &phases[1], &phases[2], &phases[3], &phases[4], &res.Attempts, &suppressed, &res.CreatedAt); err != nil {
+		return models.Result{}, err
+	}
+	res.Suppressed = suppressed == 1
+	if detailsJSON.Valid && detailsJSON.String != "" {
+		_ = json.Unmarshal([]byte(detailsJSON.String), &res.Details)
+	}
+	if phases[0].Valid {
+		res.Timings = &models.PhaseTimings{
+			DNSMS:      int(phases[0].Int64),
+			ConnectMS:  int(phases[1].Int64),
+			TLSMS:      int(phases[2].Int64),
+			TTFBMS:     int(phases[3].Int64),
+			TransferMS: int(phases[4].Int64),
+		}
+	}
+	return res, nil
+}
+
+// Add сохраняет результат и возвращает его ID.
+func (r *ResultRepo) Add(res models.Result) (int, error) {
 	timestamp := res.CreatedAt
 	if timestamp == "" {
 		timestamp = time.Now().Format(time.RFC3339)
 	}
 
-	_, err := r.db.Exec(`
-		INSERT INTO results(check_id, status, status_code, duration_ms, outcome, error_message, created_at)
-		VALUES(?, ?, ?, ?, ?, ?, ?)
-	`, res.CheckID, res.Status, res.StatusCode, res.DurationMS, res.Outcome, res.ErrorMessage, timestamp)
-	return err
+	var details sql.NullString
+	if len(res.Details) > 0 {
+		raw, err := json.Marshal(res.Details)
+		if err != nil {
+			return 0, fmt.Errorf("marshal details: %w", err)
+		}
+		details = sql.NullString{String: string(raw), Valid: true}
+	}
+
+	attempts := res.Attempts
+	if attempts < 1 {
+		attempts = 1
+	}
+
+	var phases [5]sql.NullInt64
+	if t := res.Timings; t != nil {
+		for i, v := range []int{t.DNSMS, t.ConnectMS, t.TLSMS, t.TTFBMS, t.TransferMS} {
+			phases[i] = sql.NullInt64{Int64: int64(v), Valid: true}
+		}
+	}
+
+	insertRes, err := r.db.Exec(`
+		INSERT INTO results(check_id, status, status_code, duration_ms, outcome, error_message, details,
+			dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms, attempts, suppressed, created_at)
+		VALUES(?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`, res.CheckID, res.Status, res.StatusCode, res.DurationMS, res.Outcome, res.ErrorMessage, details,
+		phases[0], phases[1], phases[2], phases[3], phases[4], attempts, boolToInt(res.Suppressed), timestamp)
+	if err != nil {
+		return 0, err
+	}
+	id, err := insertRes.LastInsertId()
+	return int(id), err
 }
 
 func (r *ResultRepo) GetByCheckID(checkID int) ([]models.Result, error) {
 	rows, err := r.db.Query(`
-		SELECT id, check_id, status, status_code, duration_ms, outcome, error_message, created_at
+		SELECT `+resultColumns+`
 		FROM results
 		WHERE check_id = ?
 		ORDER BY created_at DESC
@@ -42,8 +113,8 @@ func (r *ResultRepo) GetByCheckID(checkID int) ([]models.Result, error) {
 
 	var results []models.Result
 	for rows.Next() {
-		var res models.Result
-		if err := rows.Scan(&res.ID, &res.CheckID, &res.Status, &res.StatusCode, &res.DurationMS, &res.Outcome, &res.ErrorMessage, &res.CreatedAt); err != nil {
+		res, err := scanResult(rows)
+		if err != nil {
 			return nil, err
 		}
 		results = append(results, res)
@@ -51,9 +122,26 @@ func (r *ResultRepo) GetByCheckID(checkID int) ([]models.Result, error) {
 	return results, rows.Err()
 }
 
+// GetLastRunTime возвращает время последнего результата проверки; found=false, если результатов ещё нет.
+func (r *ResultRepo) GetLastRunTime(checkID int) (last time.Time, found bool, err error) {
+	var createdAt string
+	err = r.db.QueryRow(`SELECT created_at FROM results WHERE check_id = ? ORDER BY created_at DESC LIMIT 1`, checkID).Scan(&createdAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return time.Time{}, false, nil
+	}
+	if err != nil {
+		return time.Time{}, false, err
+	}
+	last, err = time.Parse(time.RFC3339, createdAt)
+	if err != nil {
+		return time.Time{}, false, fmt.Errorf("parse created_at %q: %w", createdAt, err)
+	}
+	return last, true, nil
+}
+
 func (r *ResultRepo) GetAll() ([]models.Result, error) {
 	rows, err := r.db.Query(`
-		SELECT id, check_id, status, status_code, duration_ms, outcome, error_message, created_at
+		SELECT ` + resultColumns + `
 		FROM results
 		ORDER BY created_at DESC
 	`)
@@ -64,8 +152,8 @@ func (r *ResultRepo) GetAll() ([]models.Result, error) {
 
 	var results []models.Result
 	for rows.Next() {
-		var res models.Result
-		if err := rows.Scan(&res.ID, &res.CheckID, &res.Status, &res.StatusCode, &res.DurationMS, &res.Outcome, &res.ErrorMessage, &res.CreatedAt); err != nil {
+		res, err := scanResult(rows)
+		if err != nil {
 			return nil, err
 		}
 		results = append(results, res)
@@ -75,13 +163,11 @@ func (r *ResultRepo) GetAll() ([]models.Result, error) {
 
 func (r *ResultRepo) GetByID(id int) (models.Result, error) {
 	row := r.db.QueryRow(`
-		SELECT id, check_id, status, status_code, duration_ms, outcome, error_message, created_at
+		SELECT `+resultColumns+`
 		FROM results
 		WHERE id = ?
 	`, id)
-	var res models.Result
-	err := row.Scan(&res.ID, &res.CheckID, &res.Status, &res.StatusCode, &res.DurationMS, &res.Outcome, &res.ErrorMessage, &res.CreatedAt)
-	return res, err
+	return scanResult(row)
 }
 
 func (r *ResultRepo) GetByCheckIDWithPagination(checkID int, from, to *time.Time, page, pageSize int) ([]models.Result, int, error) {
@@ -98,7 +184,7 @@ func (r *ResultRepo) GetByCheckIDWithPagination(checkID int, from, to *time.Time
 	offset := (page - 1) * pageSize
 
 	query := `
-		SELECT id, check_id, status, status_code, duration_ms, outcome, error_message, created_at
+		SELECT ` + resultColumns + `
 		FROM results
 		WHERE check_id = ?
 	`
@@ -124,8 +210,8 @@ func (r *ResultRepo) GetByCheckIDWithPagination(checkID int, from, to *time.Time
 
 	var results []models.Result
 	for rows.Next() {
-		var res models.Result
-		if err := rows.Scan(&res.ID, &res.CheckID, &res.Status, &res.StatusCode, &res.DurationMS, &res.Outcome, &res.ErrorMessage, &res.CreatedAt); err != nil {
+		res, err := scanResult(rows)
+		if err != nil {
 			return nil, 0, err
 		}
 		results = append(results, res)
@@ -156,43 +242,46 @@ func (r *ResultRepo) GetByCheckIDWithPagination(checkID int, from, to *time.Time
 }
 
 type Stats struct {
-	TotalResults       int                 `json:"total_results"`
-	StatusDistribution map[string]int      `json:"status_distribution"`
-	LatencyStats       models.LatencyStats `json:"latency_stats"`
+	TotalResults       int                            `json:"total_results"`
+	StatusDistribution map[string]int                 `json:"status_distribution"`
+	LatencyStats       models.LatencyStats            `json:"latency_stats"`
+	PhaseStats         map[string]models.LatencyStats `json:"phase_stats,omitempty"`
 }
 
+// GetStats считает статистику проверки без учёта окон обслуживания и пропущенных запусков:
+// результаты со статусами maintenance и skipped и результаты, попавшие в действующие окна, пропускаются.
 func (r *ResultRepo) GetStats(checkID int, from, to *time.Time) (Stats, error) {
 	var stats Stats
 	stats.StatusDistribution = make(map[string]int)
 
-	query := "SELECT status, duration_ms FROM results WHERE check_id = ?"
-	args := []any{checkID}
-
-	if from != nil {
-		query += " AND datetime(created_at) >= datetime(?)"
-		args = append(args, from.Format(time.RFC3339))
-	}
-	if to != nil {
-		query += " AND datetime(created_at) <= datetime(?)"
-		args = append(args, to.Format(time.RFC3339))
+	filter, args, err := r.uptimeFilter(checkID, from, to)
+	if err != nil {
+		return stats, err
 	}
 
-	rows, err := r.db.Query(query, args...)
+	rows, err := r.db.Query("SELECT status, duration_ms, dns_ms, connect_ms, tls_ms, ttfb_ms, transfer_ms FROM results WHERE "+filter, args...)
 	if err != nil {
 		return stats, err
 	}
 	defer rows.Close()
 
 	var durations []int
+	phaseDurations := make([][]int, len(phaseNames))
 	for rows.Next() {
 		var status string
 		var duration int
-		if err := rows.Scan(&status, &duration); err != nil {
+		var phases [5]sql.NullInt64
+		if err := rows.Scan(&status, &duration, &phases[0], &phases[1], &phases[2], &phases[3], &phases[4]); err != nil {
 			return stats, err
 		}
 		stats.StatusDistribution[status]++
 		stats.TotalResults++
 		durations = append(durations, duration)
+		for i, p := range phases {
+			if p.Valid {
+				phaseDurations[i] = append(phaseDurations[i], int(p.Int64))
+			}
+		}
 	}
 
 	if err := rows.Err(); err != nil {
@@ -204,6 +293,15 @@ func (r *ResultRepo) GetStats(checkID int, from, to *time.Time) (Stats, error) {
 	}
 
 	stats.LatencyStats = calculateLatencyStats(durations)
+	for i, name := range phaseNames {
+		if len(phaseDurations[i]) == 0 {
+			continue
+		}
+		if stats.PhaseStats == nil {
+			stats.PhaseStats = make(map[string]models.LatencyStats)
+		}
+		stats.PhaseStats[name] = calculateLatencyStats(phaseDurations[i])
+	}
 
 	return stats, nil
 }
@@ -266,17 +364,54 @@ func buildTimeFilter(query string, args []any, from, to *time.Time) (string, []a
 	return query, args
 }
 
+const phaseAverageColumns = "AVG(dns_ms), AVG(connect_ms), AVG(tls_ms), AVG(ttfb_ms), AVG(transfer_ms)"
+
+// phaseAverages returns nil when the bucket has no results with HTTP phase timings.
+func phaseAverages(phases [5]sql.NullFloat64) *models.PhaseAverages {
+	if !phases[0].Valid {
+		return nil
+	}
+	return &models.PhaseAverages{
+		DNS:      phases[0].Float64,
+		Connect:  phases[1].Float64,
+		TLS:      phases[2].Float64,
+		TTFB:     phases[3].Float64,
+		Transfer: phases[4].Float64,
+	}
+}
+
+// uptimeFilter возвращает условие WHERE для статистики проверки checkID (0 — всех проверок) за период:
+// результаты со статусами maintenance и skipped и результаты, попавшие в окна обслуживания, не учитываются.
+func (r *ResultRepo) uptimeFilter(checkID int, from, to *time.Time) (string, []any, error) {
+	filter := "status NOT IN (?, ?)"
+	args := []any{StatusMaintenance, StatusSkipped}
+	if checkID > 0 {
+		filter = "check_id = ? AND " + filter
+		args = append([]any{checkID}, args...)
+	}
+	filter, args = buildTimeFilter(filter, args, from, to)
+
+	ranges, err := maintenanceRanges(r.db, checkID, from, to)
+	if err != nil || ranges == "" {
+		return filter, args, err
+	}
+	filter += ` AND NOT EXISTS (
+		SELECT 1 FROM json_each(?) AS mw
+		WHERE json_extract(mw.value, '$[0]') IN (0, results.check_id)
+			AND datetime(results.created_at) >= json_extract(mw.value, '$[1]')
+			AND datetime(results.created_at) < json_extract(mw.value, '$[2]'))`
+	return filter, append(args, ranges), nil
+}
+
 // fetchStatusDistributions fetches all status distributions for the given time buckets in a single query,
 // eliminating the N+1 query pattern.
-func (r *ResultRepo) fetchStatusDistributions(timeTruncate string, checkIDFilter string, args []any, from, to *time.Time) (map[string]map[string]int, error) {
+func (r *ResultRepo) fetchStatusDistributions(timeTruncate, filter string, args []any) (map[string]map[string]int, error) {
 	statusQuery := fmt.Sprintf(`
 		SELECT %s as time_bucket, status, COUNT(*)
 		FROM results
 		WHERE %s
-	`, timeTruncate, checkIDFilter)
-
-	statusQuery, args = buildTimeFilter(statusQuery, args, from, to)
-	statusQuery += " GROUP BY time_bucket, status"
+		GROUP BY time_bucket, status
+	`, timeTruncate, filter)
 
 	rows, err := r.db.Query(statusQuery, args...)
 	if err != nil {
@@ -299,29 +434,12 @@ func (r *ResultRepo) fetchStatusDistributions(timeTruncate string, checkIDFilter
 	return result, rows.Err()
 }
 
-func (r *ResultRepo) GetByTimeInterval(checkID int, interval string, from, to *time.Time, page, pageSize int) ([]models.TimeIntervalData, int, error) {
-	if page < 1 {
-		page = 1
-	}
-	if pageSize < 1 {
-		pageSize = 100
-	}
-	if pageSize > 1000 {
-		pageSize = 1000
-	}
-
-	offset := (page - 1) * pageSize
-	var timeTruncate string
-
-	switch interval {
-	case "1m":
-		timeTruncate = "strftime('%Y-%m-%d %H:%M:00', created_at)"
-	case "5m":
-		timeTruncate = "strftime('%Y-%m-%d %H:', created_at) || printf('%02d', (CAST(strftime('%M', created_at) AS INTEGER) / 5) * 5) || ':00'"
-	case "1h":
-		timeTruncate = "strftime('%Y-%m-%d %H:00:00', created_at)"
-	default:
-		return nil, 0, fmt.Errorf("unsupported interval: %s. Supported: 1m, 5m, 1h", interval)
+// aggregateIntervals группирует результаты проверки checkID (0 — всех проверок) по интервалам timeTruncate
+// и возвращает страницу интервалов и их общее число. Результаты отбираются так же, как в GetStats.
+func (r *ResultRepo) aggregateIntervals(timeTruncate string, checkID int, from, to *time.Time, page, pageSize int) ([]models.TimeIntervalData, int, error) {
+	filter, filterArgs, err := r.uptimeFilter(checkID, from, to)
+	if err != nil {
+		return nil, 0, err
 	}
 
 	query := fmt.Sprintf(`
@@ -332,16 +450,13 @@ func (r *ResultRepo) GetByTimeInterval(checkID int, interval string, from, to *t
 			SUM(CASE WHEN status != 'success' THEN 1 ELSE 0 END) as failure_count,
 			AVG(duration_ms) as avg_latency,
 			MIN(duration_ms) as min_latency,
-			MAX(duration_ms) as max_latency
+			MAX(duration_ms) as max_latency,
+			`+phaseAverageColumns+`
 		FROM results
-		WHERE check_id = ?
-	`, timeTruncate)
-
-	args := []any{checkID}
-	query, args = buildTimeFilter(query, args, from, to)
-
-	query += " GROUP BY time_bucket ORDER BY time_bucket ASC LIMIT ? OFFSET ?"
-	args = append(args, pageSize, offset)
+		WHERE %s
+		GROUP BY time_bucket ORDER BY time_bucket ASC LIMIT ? OFFSET ?
+	`, timeTruncate, filter)
+	args := append(append([]any{}, filterArgs...), pageSize, (page-1)*pageSize)
 
 	rows, err := r.db.Query(query, args...)
 	if err != nil {
@@ -352,26 +467,26 @@ func (r *ResultRepo) GetByTimeInterval(checkID int, interval string, from, to *t
 	var results []models.TimeIntervalData
 	for rows.Next() {
 		var data models.TimeIntervalData
-		var timestamp string
-		var successCount, failureCount int
 		var avgLatency sql.NullFloat64
+		var phases [5]sql.NullFloat64
 
-		if err := rows.Scan(&timestamp, &data.Count, &successCount, &failureCount, &avgLatency, &data.MinLatency, &data.MaxLatency); err != nil {
+		if err := rows.Scan(&data.Timestamp, &data.Count, &data.SuccessCount, &data.FailureCount, &avgLatency, &data.MinLatency, &data.MaxLatency,
+			&phases[0], &phases[1], &phases[2], &phases[3], &phases[4]); err != nil {
 			return nil, 0, err
 		}
-
-		data.Timestamp = timestamp
-		data.SuccessCount = successCount
-		data.FailureCount = failureCount
 		if avgLatency.Valid {
 			data.AvgLatency = avgLatency.Float64
 		}
+		data.AvgPhases = phaseAverages(phases)
 		data.StatusDistribution = make(map[string]int)
 		results = append(results, data)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, err
+	}
 
 	// Fetch all status distributions in one query instead of N+1
-	statusDists, err := r.fetchStatusDistributions(timeTruncate, "check_id = ?", []any{checkID}, from, to)
+	statusDists, err := r.fetchStatusDistributions(timeTruncate, filter, filterArgs)
 	if err == nil {
 		for i := range results {
 			if dist, ok := statusDists[results[i].Timestamp]; ok {
@@ -380,106 +495,56 @@ func (r *ResultRepo) GetByTimeInterval(checkID int, interval string, from, to *t
 		}
 	}
 
-	countQuery := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM results WHERE check_id = ?", timeTruncate)
-	countArgs := []any{checkID}
-	countQuery, countArgs = buildTimeFilter(countQuery, countArgs, from, to)
-
 	var total int
-	err = r.db.QueryRow(countQuery, countArgs...).Scan(&total)
-	if err != nil {
+	countQuery := fmt.Sprintf("SELECT COUNT(DISTINCT %s) FROM results WHERE %s", timeTruncate, filter)
+	if err := r.db.QueryRow(countQuery, filterArgs...).Scan(&total); err != nil {
 		return nil, 0, err
 	}
 
 	if results == nil {
 		results = []models.TimeIntervalData{}
 	}
-
-	return results, total, rows.Err()
+	return results, total, nil
 }
 
-func (r *ResultRepo) GetRecentDataForAllChecks(from, to *time.Time, page, pageSize int) ([]models.TimeIntervalData, int, error) {
+func (r *ResultRepo) GetByTimeInterval(checkID int, interval string, from, to *time.Time, page, pageSize int) ([]models.TimeIntervalData, int, error) {
 	if page < 1 {
 		page = 1
 	}
 	if pageSize < 1 {
-		pageSize = 50
+		pageSize = 100
 	}
 	if pageSize > 1000 {
 		pageSize = 1000
 	}
 
-	offset := (page - 1) * pageSize
-	timeTruncate := "strftime('%Y-%m-%d %H:%M:00', created_at)"
-
-	query := fmt.Sprintf(`
-		SELECT
-			%s as time_bucket,
-			COUNT(*) as count,
-			SUM(CASE WHEN status = 'success' THEN 1 ELSE 0 END) as success_count,
-			SUM(CASE WHEN status != 'success' THEN 1 ELSE 0 END) as failure_count,
-			AVG(duration_ms) as avg_latency,
-			MIN(duration_ms) as min_latency,
-			MAX(duration_ms) as max_latency
-		FROM results
-		WHERE 1=1
-	`, timeTruncate)
-
-	args := []any{}
-	query, args = buildTimeFilter(query, args, from, to)
-
-	query += " GROUP BY time_bucket ORDER BY time_bucket ASC LIMIT ? OFFSET ?"
-	args = append(args, pageSize, offset)
+	var timeTruncate string
 
-	rows, err := r.db.Query(query, args...)
-	if err != nil {
-		return nil, 0, err
+	switch interval {
+	case "1m":
+		timeTruncate = "strftime('%Y-%m-%d %H:%M:00', created_at)"
+	case "5m":
+		timeTruncate = "strftime('%Y-%m-%d %H:', created_at) || printf('%02d', (CAST(strftime('%M', created_at) AS INTEGER) / 5) * 5) || ':00'"
+	case "1h":
+		timeTruncate = "strftime('%Y-%m-%d %H:00:00', created_at)"
+	default:
+		return nil, 0, fmt.Errorf("unsupported interval: %s. Supported: 1m, 5m, 1h", interval)
 	}
-	defer rows.Close()
-
-	var results []models.TimeIntervalData
-	for rows.Next() {
-		var data models.TimeIntervalData
-		var timestamp string
-		var successCount, failureCount int
-		var avgLatency sql.NullFloat64
 
-		if err := rows.Scan(&timestamp, &data.Count, &successCount, &failureCount, &avgLatency, &data.MinLatency, &data.MaxLatency); err != nil {
-			return nil, 0, err
-		}
-
-		data.Timestamp = timestamp
-		data.SuccessCount = successCount
-		data.FailureCount = failureCount
-		if avgLatency.Valid {
-			data.AvgLatency = avgLatency.Float64
-		}
-		data.StatusDistribution = make(map[string]int)
-		results = append(results, data)
-	}
+	return r.aggregateIntervals(timeTruncate, checkID, from, to, page, pageSize)
+}
 
-	// Fetch all status distributions in one query instead of N+1
-	statusDists, err := r.fetchStatusDistributions(timeTruncate, "1=1", []any{}, from, to)
-	if err == nil {
-		for i := range results {
-			if dist, ok := statusDists[results[i].Timestamp]; ok {
-				results[i].StatusDistribution = dist
-			}
-		}
+func (r *ResultRepo) GetRecentDataForAllChecks(from, to *time.Time, page, pageSize int) ([]models.TimeIntervalData, int, error) {
+	if page < 1 {
+		page = 1
 	}
-
-	countQuery := "SELECT COUNT(DISTINCT " + timeTruncate + ") FROM results WHERE 1=1"
-	countArgs := []any{}
-	countQuery, countArgs = buildTimeFilter(countQuery, countArgs, from, to)
-
-	var total int
-	err = r.db.QueryRow(countQuery, countArgs...).Scan(&total)
-	if err != nil {
-		return nil, 0, err
+	if pageSize < 1 {
+		pageSize = 50
 	}
-
-	if results == nil {
-		results = []models.TimeIntervalData{}
+	if pageSize > 1000 {
+		pageSize = 1000
 	}
 
-	return results, total, rows.Err()
+	timeTruncate := "strftime('%Y-%m-%d %H:%M:00', created_at)"
+	return r.aggregateIntervals(timeTruncate, 0, from, to, page, pageSize)
 }
@@ -0,0 +1,49 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+type CheckStateRepo struct {
+	db *sql.DB
+}
+
+func NewCheckStateRepo(db *sql.DB) *CheckStateRepo { return &CheckStateRepo{db: db} }
+
+// Get возвращает сохранённое состояние проверки; found=false, если проверка ещё не выполнялась.
+func (r *CheckStateRepo) Get(checkID int) (state models.CheckState, found bool, err error) {
+	var lastNotified sql.NullString
+	err = r.db.QueryRow(`
+		SELECT check_id, state, since, last_notified_at, consecutive_failures
+		FROM check_states
+		WHERE check_id = ?
+	`, checkID).Scan(&state.CheckID, &state.State, &state.Since, &lastNotified, &state.ConsecutiveFailures)
+	if errors.Is(err, sql.ErrNoRows) {
+		return models.CheckState{}, false, nil
+	}
+	if err != nil {
+		return models.CheckState{}, false, err
+	}
+	state.LastNotifiedAt = lastNotified.String
+	return state, true, nil
+}
+
+func (r *CheckStateRepo) Save(state models.CheckState) error {
+	var lastNotified sql.NullString
+	if state.LastNotifiedAt != "" {
+		lastNotified = sql.NullString{String: state.LastNotifiedAt, Valid: true}
+	}
+	_, err := r.db.Exec(`
+		INSERT INTO check_states(check_id, state, since, last_notified_at, consecutive_failures)
+		VALUES(?, ?, ?, ?, ?)
+		ON CONFLICT(check_id) DO UPDATE SET
+			state = excluded.state,
+			since = excluded.since,
+			last_notified_at = excluded.last_notified_at,
+			consecutive_failures = excluded.consecutive_failures
+	`, state.CheckID, state.State, state.Since, lastNotified, state.ConsecutiveFailures)
+	return err
+}
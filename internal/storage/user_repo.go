@@ -0,0 +1,169 @@
+package storage
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/MimoJanra/DomainPulse/internal/auth"
+	"github.com/MimoJanra/DomainPulse/internal/models"
+)
+
+var (
+	// ErrUserExists — пользователь с таким именем уже есть.
+	ErrUserExists = errors.New("user already exists")
+	// ErrLastAdmin — изменение оставило бы систему без администратора.
+	ErrLastAdmin = errors.New("cannot remove the last admin")
+)
+
+type UserRepo struct {
+	db *sql.DB
+}
+
+func NewUserRepo(db *sql.DB) *UserRepo { return &UserRepo{db: db} }
+
+const userColumns = `id, name, role, created_at`
+
+type userScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanUser(s userScanner) (models.User, error) {
+	var (
+		u         models.User
+		createdAt sql.NullString
+	)
+	if err := s.Scan(&u.ID, &u.Name, &u.Role, &createdAt); err != nil {
+		return models.User{}, err
+	}
+	u.CreatedAt = createdAt.String
+	return u, nil
+}
+
+func (r *UserRepo) Add(name, role string) (models.User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return models.User{}, err
+	}
+	defer tx.Rollback()
+
+	if err := checkUserNameFree(tx, name, 0); err != nil {
+		return models.User{}, err
+	}
+	res, err := tx.Exec(`INSERT INTO users(name, role, created_at) VALUES(?, ?, ?)`, name, role, time.Now().Format(time.RFC3339))
+	if err != nil {
+		return models.User{}, err
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return models.User{}, fmt.Errorf("last insert id: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return models.User{}, err
+	}
+	return r.GetByID(int(id))
+}
+
+// Update меняет имя и роль пользователя. Возвращает ErrLastAdmin, если это единственный администратор
+// и роль понижается.
+func (r *UserRepo) Update(id int, name, role string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkUserNameFree(tx, name, id); err != nil {
+		return err
+	}
+	if role != auth.RoleAdmin {
+		if err := checkNotLastAdmin(tx, id); err != nil {
+			return err
+		}
+	}
+	if _, err := tx.Exec(`UPDATE users SET name = ?, role = ? WHERE id = ?`, name, role, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+// Delete удаляет пользователя вместе с его ключами. Единственного администратора удалить нельзя.
+func (r *UserRepo) Delete(id int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := checkNotLastAdmin(tx, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM api_keys WHERE user_id = ?`, id); err != nil {
+		return err
+	}
+	if _, err := tx.Exec(`DELETE FROM users WHERE id = ?`, id); err != nil {
+		return err
+	}
+	return tx.Commit()
+}
+
+func checkUserNameFree(tx *sql.Tx, name string, exceptID int) error {
+	var count int
+	if err := tx.QueryRow(`SELECT COUNT(*) FROM users WHERE name = ? AND id != ?`, name, exceptID).Scan(&count); err != nil {
+		return err
+	}
+	if count > 0 {
+		return ErrUserExists
+	}
+	return nil
+}
+
+// checkNotLastAdmin возвращает ErrLastAdmin, если userID — единственный администратор.
+func checkNotLastAdmin(tx *sql.Tx, userID int) error {
+	var others int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM users WHERE role = ? AND id != ?`, auth.RoleAdmin, userID).Scan(&others)
+	if err != nil {
+		return err
+	}
+	var role string
+	err = tx.QueryRow(`SELECT role FROM users WHERE id = ?`, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if role == auth.RoleAdmin && others == 0 {
+		return ErrLastAdmin
+	}
+	return nil
+}
+
+func (r *UserRepo) GetByID(id int) (models.User, error) {
+	return scanUser(r.db.QueryRow(`SELECT `+userColumns+` FROM users WHERE id = ?`, id))
+}
+
+func (r *UserRepo) GetAll() ([]models.User, error) {
+	rows, err := r.db.Query(`SELECT ` + userColumns + ` FROM users ORDER BY id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		u, err := scanUser(rows)
+		if err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+	return users, rows.Err()
+}
+
+func (r *UserRepo) Count() (int, error) {
+	var count int
+	err := r.db.QueryRow(`SELECT COUNT(*) FROM users`).Scan(&count)
+	return count, err
+}
@@ -28,21 +28,44 @@ function showSuccess(message) {
     showToast(message, 'success');
 }
 
-async function apiCall(endpoint, options = {}) {
+// Ключ API хранится в браузере и передаётся в заголовке X-API-Key
+const API_KEY_STORAGE = 'domainpulse_api_key';
+
+function getApiKey() {
+    return localStorage.getItem(API_KEY_STORAGE) || '';
+}
+
+// Запрашивает ключ API у пользователя; возвращает true, если ключ введён
+function promptApiKey() {
+    const key = window.prompt('Введите ключ API DomainPulse');
+    if (!key || !key.trim()) return false;
+    localStorage.setItem(API_KEY_STORAGE, key.trim());
+    return true;
+}
+
+async function apiCall(endpoint, options = {}, retried = false) {
     try {
         const url = API_BASE + endpoint;
         console.log('API Call:', url, options.method || 'GET');
         
+        const apiKey = getApiKey();
         const response = await fetch(url, {
+            ...options,
             headers: {
                 'Content-Type': 'application/json',
+                ...(apiKey ? { 'X-API-Key': apiKey } : {}),
                 ...options.headers
-            },
-            ...options
+            }
         });
         
         console.log('API Response status:', response.status, response.statusText);
         
+        // Ключ не задан или недействителен — запрашиваем его (если его ещё не сменил параллельный запрос)
+        // и повторяем запрос один раз
+        if (response.status === 401 && !retried && (getApiKey() !== apiKey || promptApiKey())) {
+            return apiCall(endpoint, options, true);
+        }
+        
         if (!response.ok) {
             const errorText = await response.text();
             console.error('API Error response:', errorText);
@@ -323,7 +346,8 @@ function createCheckElement(check) {
         icmp: 'bg-primary',
         tcp: 'bg-warning text-dark',
         udp: 'bg-success',
-        tls: 'bg-dark'
+        tls: 'bg-dark',
+        dns: 'bg-secondary'
     };
     const typeBadge = typeBadges[checkType] || 'bg-secondary';
     const statusBadge = check.enabled ? 'bg-success' : 'bg-secondary';
@@ -413,6 +437,7 @@ function updateCheckForm() {
     const portParams = document.getElementById('portParams');
     const tcpParams = document.getElementById('tcpParams');
     const udpParams = document.getElementById('udpParams');
+    const dnsParams = document.getElementById('dnsParams');
     const portInput = document.getElementById('checkPort');
 
     const needsPort = type === 'tcp' || type === 'udp' || type === 'tls';
@@ -420,6 +445,7 @@ function updateCheckForm() {
     portParams.style.display = needsPort ? 'block' : 'none';
     tcpParams.style.display = type === 'tcp' ? 'block' : 'none';
     udpParams.style.display = type === 'udp' ? 'block' : 'none';
+    dnsParams.style.display = type === 'dns' ? 'block' : 'none';
     if (portInput) portInput.required = needsPort;
 }
 
@@ -429,6 +455,7 @@ function updateEditCheckForm() {
     const portParams = document.getElementById('editPortParams');
     const tcpParams = document.getElementById('editTcpParams');
     const udpParams = document.getElementById('editUdpParams');
+    const dnsParams = document.getElementById('editDnsParams');
     const portInput = document.getElementById('editCheckPort');
 
     const needsPort = type === 'tcp' || type === 'udp' || type === 'tls';
@@ -436,6 +463,7 @@ function updateEditCheckForm() {
     portParams.style.display = needsPort ? 'block' : 'none';
     tcpParams.style.display = type === 'tcp' ? 'block' : 'none';
     udpParams.style.display = type === 'udp' ? 'block' : 'none';
+    dnsParams.style.display = type === 'dns' ? 'block' : 'none';
     if (portInput) portInput.required = needsPort;
 }
 
@@ -486,6 +514,14 @@ document.getElementById('addCheckForm').addEventListener('submit', async (e) =>
         const payload = document.getElementById('checkPayload').value;
         if (payload) params.payload = payload;
     }
+    if (type === 'dns') {
+        params.record_type = document.getElementById('checkDnsRecordType').value || 'A';
+        const resolver = document.getElementById('checkDnsResolver').value.trim();
+        if (resolver) params.resolver = resolver;
+        const expected = document.getElementById('checkDnsExpectedValues').value
+            .split(',').map(v => v.trim()).filter(v => v);
+        if (expected.length) params.expected_values = expected;
+    }
     if (timeout > 0) {
         params.timeout_ms = timeout;
     }
@@ -566,6 +602,11 @@ async function editCheck(checkId) {
             if (check.type === 'udp') {
                 document.getElementById('editCheckPayload').value = check.params.payload || '';
             }
+            if (check.type === 'dns') {
+                document.getElementById('editCheckDnsRecordType').value = check.params.record_type || 'A';
+                document.getElementById('editCheckDnsResolver').value = check.params.resolver || '';
+                document.getElementById('editCheckDnsExpectedValues').value = (check.params.expected_values || []).join(', ');
+            }
             document.getElementById('editCheckTimeout').value = check.params.timeout_ms || 5000;
         }
         
@@ -625,6 +666,14 @@ document.getElementById('editCheckForm').addEventListener('submit', async (e) =>
         const payload = document.getElementById('editCheckPayload').value;
         if (payload) params.payload = payload;
     }
+    if (type === 'dns') {
+        params.record_type = document.getElementById('editCheckDnsRecordType').value || 'A';
+        const resolver = document.getElementById('editCheckDnsResolver').value.trim();
+        if (resolver) params.resolver = resolver;
+        const expected = document.getElementById('editCheckDnsExpectedValues').value
+            .split(',').map(v => v.trim()).filter(v => v);
+        if (expected.length) params.expected_values = expected;
+    }
     if (timeout > 0) {
         params.timeout_ms = timeout;
     }
@@ -795,6 +844,8 @@ function aggregateResultsByMinute(results, isHttpCheck = false) {
     // Агрегируем результаты
     for (const result of results) {
         if (!result.created_at) continue;
+        // Результаты во время окна обслуживания и пропущенные запуски не учитываются
+        if (result.status === 'maintenance' || result.status === 'skipped') continue;
         
         const resultDate = new Date(result.created_at);
         // Округляем до минуты